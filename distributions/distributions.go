@@ -0,0 +1,169 @@
+/*
+Package distributions provides the cumulative distribution, survival and
+quantile functions of the distributions used for inference on linear models.
+
+The survival function is calculated directly rather than as 1 - CDF, so small
+upper tail probabilities (i.e. p-values) keep their precision.
+*/
+package distributions
+
+import "math"
+
+// A continuous univariate distribution
+type Distribution interface {
+	// P(X <= x)
+	CDF(x float64) float64
+	// P(X > x)
+	Survival(x float64) float64
+	// The value of x such that P(X <= x) = p
+	Quantile(p float64) float64
+}
+
+// The normal distribution with mean `Mu` and standard deviation `Sigma`
+type Normal struct {
+	Mu, Sigma float64
+}
+
+// The standard normal distribution
+var StdNormal = Normal{Mu: 0, Sigma: 1}
+
+func (d Normal) CDF(x float64) float64 {
+	return 0.5 * math.Erfc(-(x-d.Mu)/(d.Sigma*math.Sqrt2))
+}
+
+func (d Normal) Survival(x float64) float64 {
+	return 0.5 * math.Erfc((x-d.Mu)/(d.Sigma*math.Sqrt2))
+}
+
+func (d Normal) Quantile(p float64) float64 {
+	if p < 0 || p > 1 {
+		return math.NaN()
+	}
+	return d.Mu - d.Sigma*math.Sqrt2*math.Erfcinv(2*p)
+}
+
+// Student's t distribution with `Nu` degrees of freedom
+type StudentsT struct {
+	Nu float64
+}
+
+func (d StudentsT) CDF(x float64) float64 {
+	return d.Survival(-x)
+}
+
+func (d StudentsT) Survival(x float64) float64 {
+	p := 0.5 * BetaInc(d.Nu/2, 0.5, d.Nu/(d.Nu+x*x))
+	if x < 0 {
+		return 1 - p
+	}
+	return p
+}
+
+func (d StudentsT) Quantile(p float64) float64 {
+	switch {
+	case p < 0 || p > 1:
+		return math.NaN()
+	case p == 0:
+		return math.Inf(-1)
+	case p == 1:
+		return math.Inf(1)
+	case p == 0.5:
+		return 0
+	case p > 0.5: // Symmetric, so only solve in the lower tail where CDF is accurate
+		return -d.Quantile(1 - p)
+	}
+	return invert(d, p, math.Inf(-1), math.Inf(1))
+}
+
+// The F distribution with `D1` and `D2` degrees of freedom
+type F struct {
+	D1, D2 float64
+}
+
+func (d F) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return BetaInc(d.D1/2, d.D2/2, d.D1*x/(d.D1*x+d.D2))
+}
+
+func (d F) Survival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return BetaInc(d.D2/2, d.D1/2, d.D2/(d.D2+d.D1*x))
+}
+
+func (d F) Quantile(p float64) float64 {
+	return invert(d, p, 0, math.Inf(1))
+}
+
+// The chi-squared distribution with `K` degrees of freedom
+type ChiSquared struct {
+	K float64
+}
+
+func (d ChiSquared) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return GammaInc(d.K/2, x/2)
+}
+
+func (d ChiSquared) Survival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return GammaIncUpper(d.K/2, x/2)
+}
+
+func (d ChiSquared) Quantile(p float64) float64 {
+	return invert(d, p, 0, math.Inf(1))
+}
+
+// Find the quantile of `d` with support [lower, upper] numerically. The bracket
+// is grown until it contains the answer and then bisected.
+func invert(d Distribution, p, lower, upper float64) float64 {
+	switch {
+	case p < 0 || p > 1 || math.IsNaN(p):
+		return math.NaN()
+	case p == 0:
+		return lower
+	case p == 1:
+		return upper
+	}
+
+	// Increasing in x, and uses whichever tail keeps the most precision
+	f := func(x float64) float64 {
+		if p < 0.5 {
+			return d.CDF(x) - p
+		}
+		return (1 - p) - d.Survival(x)
+	}
+
+	lo, hi := -1.0, 1.0
+	if !math.IsInf(lower, -1) {
+		lo = lower
+	}
+	for f(hi) < 0 {
+		lo = hi
+		hi *= 2
+	}
+	for f(lo) > 0 {
+		hi = lo
+		lo *= 2
+	}
+
+	for hi-lo > eps*max(math.Abs(lo), math.Abs(hi)) {
+		mid := lo + (hi-lo)/2
+		if mid <= lo || mid >= hi {
+			break // No more precision to be had
+		}
+		if f(mid) < 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo + (hi-lo)/2
+}
@@ -0,0 +1,126 @@
+package distributions
+
+import (
+	"math"
+	"testing"
+)
+
+// Reference values are from R's pnorm, pt, pf, pchisq and friends, or closed forms
+func near(got, want, tol float64) bool {
+	if math.IsInf(want, 0) {
+		return got == want
+	}
+	return math.Abs(got-want) <= tol*math.Max(1, math.Abs(want))
+}
+
+func TestCDF(t *testing.T) {
+	type TestCase struct {
+		desc string
+		dist Distribution
+		x    float64
+		want float64
+	}
+
+	test_cases := []TestCase{
+		{desc: "normal at the mean", dist: StdNormal, x: 0, want: 0.5},
+		{desc: "normal at 1.96", dist: StdNormal, x: 1.959963984540054, want: 0.975},
+		{desc: "shifted normal", dist: Normal{Mu: 10, Sigma: 2}, x: 12, want: 0.8413447460685429},
+		{desc: "t with 1 df is Cauchy", dist: StudentsT{Nu: 1}, x: 2, want: 0.5 + math.Atan(2)/math.Pi},
+		{desc: "t with 2 df", dist: StudentsT{Nu: 2}, x: -1.5, want: 0.5 - 1.5/(2*math.Sqrt(2+1.5*1.5))},
+		{desc: "t with 10 df", dist: StudentsT{Nu: 10}, x: 2.228138851986274, want: 0.975},
+		{desc: "F with 2 numerator df", dist: F{D1: 2, D2: 7}, x: 3, want: 1 - math.Pow(1+2*3.0/7, -3.5)},
+		{desc: "F with 1 and 1 df", dist: F{D1: 1, D2: 1}, x: 1, want: 0.5},
+		{desc: "chi-squared with 2 df is exponential", dist: ChiSquared{K: 2}, x: 3, want: 1 - math.Exp(-1.5)},
+		{desc: "chi-squared with 1 df", dist: ChiSquared{K: 1}, x: 3.841458820694124, want: 0.95},
+		{desc: "chi-squared below the support", dist: ChiSquared{K: 3}, x: -1, want: 0},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got := test_case.dist.CDF(test_case.x)
+			if !near(got, test_case.want, 1e-10) {
+				t.Errorf("CDF: got %v, want %v", got, test_case.want)
+			}
+			got = test_case.dist.Survival(test_case.x)
+			if !near(got, 1-test_case.want, 1e-10) {
+				t.Errorf("Survival: got %v, want %v", got, 1-test_case.want)
+			}
+		})
+	}
+}
+
+func TestSurvivalTail(t *testing.T) {
+	// These would be 0 if calculated as 1 - CDF
+	type TestCase struct {
+		desc string
+		dist Distribution
+		x    float64
+		want float64
+	}
+
+	test_cases := []TestCase{
+		{desc: "normal", dist: StdNormal, x: 10, want: 7.619853024160527e-24},
+		{desc: "t with 1 df", dist: StudentsT{Nu: 1}, x: 1e10, want: 1 / (math.Pi * 1e10)},
+		{desc: "chi-squared with 2 df", dist: ChiSquared{K: 2}, x: 100, want: math.Exp(-50)},
+		{desc: "F with 2 numerator df", dist: F{D1: 2, D2: 10}, x: 1e4, want: math.Pow(1+2*1e4/10, -5)},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got := test_case.dist.Survival(test_case.x)
+			if math.Abs(got-test_case.want) > 1e-8*test_case.want {
+				t.Errorf("got %v, want %v", got, test_case.want)
+			}
+		})
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	type TestCase struct {
+		desc string
+		dist Distribution
+		p    float64
+		want float64
+	}
+
+	test_cases := []TestCase{
+		{desc: "normal 97.5%", dist: StdNormal, p: 0.975, want: 1.959963984540054},
+		{desc: "normal 2.5%", dist: StdNormal, p: 0.025, want: -1.959963984540054},
+		{desc: "t 97.5% with 10 df", dist: StudentsT{Nu: 10}, p: 0.975, want: 2.228138851986274},
+		{desc: "t 97.5% with 1 df", dist: StudentsT{Nu: 1}, p: 0.975, want: 12.70620473617471},
+		{desc: "t 5% with 36 df", dist: StudentsT{Nu: 36}, p: 0.05, want: -1.688297713514},
+		{desc: "t median", dist: StudentsT{Nu: 5}, p: 0.5, want: 0},
+		{desc: "F 95% with 2 and 10 df", dist: F{D1: 2, D2: 10}, p: 0.95, want: 4.102821015130399},
+		{desc: "F 95% with 1 and 1 df", dist: F{D1: 1, D2: 1}, p: 0.95, want: 161.4476387714878},
+		{desc: "chi-squared 95% with 1 df", dist: ChiSquared{K: 1}, p: 0.95, want: 3.841458820694124},
+		{desc: "chi-squared 95% with 10 df", dist: ChiSquared{K: 10}, p: 0.95, want: 18.30703805327515},
+		{desc: "chi-squared 0%", dist: ChiSquared{K: 10}, p: 0, want: 0},
+		{desc: "t 100%", dist: StudentsT{Nu: 3}, p: 1, want: math.Inf(1)},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got := test_case.dist.Quantile(test_case.p)
+			if !near(got, test_case.want, 1e-9) {
+				t.Errorf("got %v, want %v", got, test_case.want)
+			}
+		})
+	}
+
+	t.Run("quantile inverts the CDF", func(t *testing.T) {
+		dists := []Distribution{StdNormal, StudentsT{Nu: 4.5}, F{D1: 3, D2: 17}, ChiSquared{K: 7}}
+		for _, d := range dists {
+			for _, p := range []float64{1e-6, 0.01, 0.3, 0.5, 0.9, 0.999} {
+				if got := d.CDF(d.Quantile(p)); !near(got, p, 1e-10) {
+					t.Errorf("%#v: CDF(Quantile(%v)) = %v", d, p, got)
+				}
+			}
+		}
+	})
+
+	t.Run("invalid probabilities", func(t *testing.T) {
+		if got := (F{D1: 1, D2: 2}).Quantile(1.5); !math.IsNaN(got) {
+			t.Errorf("expected NaN, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package distributions
+
+import "math"
+
+const (
+	maxIter = 500
+	eps     = 1e-15
+	tiny    = 1e-300
+)
+
+// The regularised incomplete beta function I_x(a, b)
+func BetaInc(a, b, x float64) float64 {
+	if math.IsNaN(x) || a <= 0 || b <= 0 {
+		return math.NaN()
+	}
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+
+	// The continued fraction converges quickly for x < (a+1)/(a+b+2), otherwise use the symmetry
+	if x < (a+1)/(a+b+2) {
+		return front * betaCF(a, b, x) / a
+	}
+	return 1 - front*betaCF(b, a, 1-x)/b
+}
+
+// Continued fraction for the incomplete beta function by the modified Lentz's method
+func betaCF(a, b, x float64) float64 {
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIter; m++ {
+		m := float64(m)
+
+		// Even step
+		num := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// Odd step
+		num = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// The regularised lower incomplete gamma function P(a, x)
+func GammaInc(a, x float64) float64 {
+	if math.IsNaN(x) || a <= 0 {
+		return math.NaN()
+	}
+	if x <= 0 {
+		return 0
+	}
+	if x < a+1 {
+		return gammaSeries(a, x)
+	}
+	return 1 - gammaCF(a, x)
+}
+
+// The regularised upper incomplete gamma function Q(a, x) = 1 - P(a, x)
+func GammaIncUpper(a, x float64) float64 {
+	if math.IsNaN(x) || a <= 0 {
+		return math.NaN()
+	}
+	if x <= 0 {
+		return 1
+	}
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaCF(a, x)
+}
+
+// Series representation of P(a, x), converges quickly for x < a+1
+func gammaSeries(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+
+	ap := a
+	sum := 1 / a
+	del := sum
+	for n := 0; n < maxIter; n++ {
+		ap++
+		del *= x / ap
+		sum += del
+		if math.Abs(del) < math.Abs(sum)*eps {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lg)
+}
+
+// Continued fraction representation of Q(a, x), converges quickly for x > a+1
+func gammaCF(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i <= maxIter; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
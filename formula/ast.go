@@ -0,0 +1,101 @@
+package formula
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A node in the parsed formula tree
+type Node interface {
+	Pos() int
+	String() string
+}
+
+// A reference to a variable (column) by name
+type Var struct {
+	Name string
+	At   int
+}
+
+// A literal number, in a formula only 0 and 1 have meaning (the intercept)
+type Num struct {
+	Text string
+	At   int
+}
+
+// A unary operator such as the leading `-` in `y ~ -1 + x`
+type Unary struct {
+	Op Kind
+	X  Node
+	At int
+}
+
+// A binary operator such as `+`, `-`, `:` or `*`
+type Binary struct {
+	Op   Kind
+	L, R Node
+	At   int
+}
+
+// A function call, the only one understood is `factor(x)`
+type Call struct {
+	Func string
+	Args []Node
+	At   int
+}
+
+// A parenthesised sub-expression
+type Paren struct {
+	X  Node
+	At int
+}
+
+func (n *Var) Pos() int    { return n.At }
+func (n *Num) Pos() int    { return n.At }
+func (n *Unary) Pos() int  { return n.At }
+func (n *Binary) Pos() int { return n.At }
+func (n *Call) Pos() int   { return n.At }
+func (n *Paren) Pos() int  { return n.At }
+
+func (n *Var) String() string   { return n.Name }
+func (n *Num) String() string   { return n.Text }
+func (n *Unary) String() string { return fmt.Sprintf("%s%s", opText[n.Op], n.X) }
+func (n *Paren) String() string { return fmt.Sprintf("(%s)", n.X) }
+func (n *Call) String() string {
+	args := make([]string, len(n.Args))
+	for i, arg := range n.Args {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("%s(%s)", n.Func, strings.Join(args, ", "))
+}
+func (n *Binary) String() string {
+	if n.Op == Colon {
+		return fmt.Sprintf("%s:%s", n.L, n.R)
+	}
+	return fmt.Sprintf("%s %s %s", n.L, opText[n.Op], n.R)
+}
+
+var opText = map[Kind]string{
+	Plus:  "+",
+	Minus: "-",
+	Colon: ":",
+	Star:  "*",
+}
+
+// Call `fn` on `n` and then on all of its children
+func Walk(n Node, fn func(Node)) {
+	fn(n)
+	switch n := n.(type) {
+	case *Unary:
+		Walk(n.X, fn)
+	case *Binary:
+		Walk(n.L, fn)
+		Walk(n.R, fn)
+	case *Call:
+		for _, arg := range n.Args {
+			Walk(arg, fn)
+		}
+	case *Paren:
+		Walk(n.X, fn)
+	}
+}
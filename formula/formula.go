@@ -0,0 +1,250 @@
+/*
+Package formula parses model formulae in the style of R, for example
+
+	y ~ x1 + x2 - 1
+	y ~ x1 * x2 + x3:x4
+	y ~ x1 + factor(x2)
+
+into a response variable and the list of terms on the right hand side.
+`a:b` is the interaction of `a` and `b`, and `a*b` is short for `a + b + a:b`.
+`factor(x)` declares `x` to be categorical regardless of its values.
+*/
+package formula
+
+import (
+	"slices"
+	"strings"
+)
+
+// A term is a set of variables multiplied together
+type Term []string
+
+func (t Term) String() string {
+	return strings.Join(t, ":")
+}
+
+// Terms are the same regardless of the order of their variables
+func (t Term) key() string {
+	s := slices.Clone(t)
+	slices.Sort(s)
+	return strings.Join(s, "\x00")
+}
+
+// A parsed formula alongside its syntax tree
+type Formula struct {
+	Response  string
+	Terms     []Term
+	Intercept bool
+	// Variables declared with `factor(x)`
+	Factors map[string]bool
+
+	LHS, RHS Node
+}
+
+// Return the formula in a canonical form, i.e. `y ~ x1 + x2`
+func (f *Formula) String() string {
+	var b strings.Builder
+	b.WriteString(f.Response)
+	b.WriteString(" ~ ")
+	for i, t := range f.Terms {
+		if i > 0 {
+			b.WriteString(" + ")
+		}
+		for j, v := range t {
+			if j > 0 {
+				b.WriteString(":")
+			}
+			if f.Factors[v] {
+				v = "factor(" + v + ")"
+			}
+			b.WriteString(v)
+		}
+	}
+	switch {
+	case len(f.Terms) == 0 && f.Intercept:
+		b.WriteString("1")
+	case len(f.Terms) == 0:
+		b.WriteString("0")
+	case !f.Intercept:
+		b.WriteString(" - 1")
+	}
+	return b.String()
+}
+
+// Parse a formula such as `y ~ x1 + x2`
+func Parse(src string) (*Formula, error) {
+	tokens, err := Lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+	lhs, rhs, err := p.parseFormula()
+	if err != nil {
+		return nil, err
+	}
+
+	response := lhs.(*Var).Name
+	factors := make(map[string]bool)
+	Walk(rhs, func(n Node) {
+		switch n := n.(type) {
+		case *Var:
+			if n.Name == response && err == nil {
+				err = errorf(n.At, "response '%s' also appears on the right hand side", response)
+			}
+		case *Call:
+			factors[n.Args[0].(*Var).Name] = true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := expand(rhs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like R, main effects come first, then two-way interactions and so on
+	slices.SortStableFunc(s.terms, func(a, b Term) int {
+		return len(a) - len(b)
+	})
+
+	return &Formula{
+		Response:  response,
+		Terms:     s.terms,
+		Intercept: s.intercept != interceptOff,
+		Factors:   factors,
+		LHS:       lhs,
+		RHS:       rhs,
+	}, nil
+}
+
+// Whether the intercept has been explicitly added or removed
+type intercept int
+
+const (
+	interceptUnset intercept = iota
+	interceptOn
+	interceptOff
+)
+
+// The result of expanding part of the right hand side of a formula
+type termSet struct {
+	terms     []Term
+	intercept intercept
+}
+
+func (s *termSet) add(t Term) {
+	k := t.key()
+	for _, u := range s.terms {
+		if u.key() == k {
+			return // Terms only ever appear once
+		}
+	}
+	s.terms = append(s.terms, t)
+}
+
+func (s *termSet) remove(t Term) {
+	k := t.key()
+	s.terms = slices.DeleteFunc(s.terms, func(u Term) bool {
+		return u.key() == k
+	})
+}
+
+// Turn a syntax tree into the (ordered) set of terms it represents
+func expand(n Node) (termSet, error) {
+	switch n := n.(type) {
+	case *Var:
+		return termSet{terms: []Term{{n.Name}}}, nil
+	case *Num:
+		switch n.Text {
+		case "1":
+			return termSet{intercept: interceptOn}, nil
+		case "0":
+			return termSet{intercept: interceptOff}, nil
+		}
+		return termSet{}, errorf(n.At, "invalid number '%s', only 0 or 1 are allowed", n.Text)
+	case *Paren:
+		return expand(n.X)
+	case *Call: // factor(x) is just the variable x
+		return expand(n.Args[0])
+	case *Unary:
+		x, err := expand(n.X)
+		if err != nil {
+			return termSet{}, err
+		}
+		if n.Op == Plus {
+			return x, nil
+		}
+		return subtract(termSet{}, x), nil
+	case *Binary:
+		l, err := expand(n.L)
+		if err != nil {
+			return termSet{}, err
+		}
+		r, err := expand(n.R)
+		if err != nil {
+			return termSet{}, err
+		}
+		switch n.Op {
+		case Minus:
+			return subtract(l, r), nil
+		case Colon:
+			return interact(n, l, r)
+		case Star:
+			lr, err := interact(n, l, r)
+			if err != nil {
+				return termSet{}, err
+			}
+			return union(union(l, r), lr), nil
+		}
+		return union(l, r), nil
+	}
+	return termSet{}, errorf(n.Pos(), "unexpected '%s'", n)
+}
+
+// Add the terms in `r` to `l`, the intercept of `r` takes precedence
+func union(l, r termSet) termSet {
+	for _, t := range r.terms {
+		l.add(t)
+	}
+	if r.intercept != interceptUnset {
+		l.intercept = r.intercept
+	}
+	return l
+}
+
+// Every term in `l` interacted with every term in `r`
+func interact(n *Binary, l, r termSet) (termSet, error) {
+	if len(l.terms) == 0 || len(r.terms) == 0 {
+		return termSet{}, errorf(n.At, "'%s' needs variables on both sides", opText[n.Op])
+	}
+	var s termSet
+	for _, a := range l.terms {
+		for _, b := range r.terms {
+			t := slices.Clone(a)
+			for _, v := range b {
+				if !slices.Contains(t, v) { // x:x is just x
+					t = append(t, v)
+				}
+			}
+			s.add(t)
+		}
+	}
+	return s, nil
+}
+
+// Remove the terms in `r` from `l`, `- 1` removes the intercept and `- 0` adds it
+func subtract(l, r termSet) termSet {
+	for _, t := range r.terms {
+		l.remove(t)
+	}
+	switch r.intercept {
+	case interceptOn:
+		l.intercept = interceptOff
+	case interceptOff:
+		l.intercept = interceptOn
+	}
+	return l
+}
@@ -0,0 +1,242 @@
+package formula
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	t.Run("lex a simple formula", func(t *testing.T) {
+		got, err := Lex("y ~ x1 + `x 2` - 1")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := []Token{
+			{Kind: Ident, Text: "y", Pos: 0},
+			{Kind: Tilde, Text: "~", Pos: 2},
+			{Kind: Ident, Text: "x1", Pos: 4},
+			{Kind: Plus, Text: "+", Pos: 7},
+			{Kind: Ident, Text: "x 2", Pos: 9},
+			{Kind: Minus, Text: "-", Pos: 15},
+			{Kind: Number, Text: "1", Pos: 17},
+			{Kind: EOF, Pos: 18},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("fail on unknown characters", func(t *testing.T) {
+		_, err := Lex("y ~ x1 & x2")
+		var ferr *Error
+		if !errors.As(err, &ferr) {
+			t.Fatalf("expected a formula error, got %v", err)
+		}
+		if ferr.Pos != 7 {
+			t.Errorf("expected error at 7, got %d", ferr.Pos)
+		}
+	})
+
+	t.Run("fail on unterminated quotes", func(t *testing.T) {
+		_, err := Lex("y ~ `x1")
+		if err == nil {
+			t.Errorf("expected lex to fail")
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	type TestCase struct {
+		desc      string
+		input     string
+		response  string
+		terms     []Term
+		intercept bool
+	}
+
+	test_cases := []TestCase{
+		{
+			desc:      "simple additive formula",
+			input:     "y ~ x1 + x2 + x3",
+			response:  "y",
+			terms:     []Term{{"x1"}, {"x2"}, {"x3"}},
+			intercept: true,
+		},
+		{
+			desc:      "removing the intercept with - 1",
+			input:     "y ~ x1 + x2 - 1",
+			response:  "y",
+			terms:     []Term{{"x1"}, {"x2"}},
+			intercept: false,
+		},
+		{
+			desc:      "removing the intercept with + 0",
+			input:     "y ~ 0 + x1",
+			response:  "y",
+			terms:     []Term{{"x1"}},
+			intercept: false,
+		},
+		{
+			desc:      "leading minus",
+			input:     "y ~ -1 + x1",
+			response:  "y",
+			terms:     []Term{{"x1"}},
+			intercept: false,
+		},
+		{
+			desc:      "removing a term",
+			input:     "y ~ (x1 + x2 + x3) - x2",
+			response:  "y",
+			terms:     []Term{{"x1"}, {"x3"}},
+			intercept: true,
+		},
+		{
+			desc:      "duplicated terms only appear once",
+			input:     "y ~ x1 + x1",
+			response:  "y",
+			terms:     []Term{{"x1"}},
+			intercept: true,
+		},
+		{
+			desc:      "interaction",
+			input:     "y ~ x1:x2",
+			response:  "y",
+			terms:     []Term{{"x1", "x2"}},
+			intercept: true,
+		},
+		{
+			desc:      "star expands to main effects and interaction",
+			input:     "y ~ x1*x2",
+			response:  "y",
+			terms:     []Term{{"x1"}, {"x2"}, {"x1", "x2"}},
+			intercept: true,
+		},
+		{
+			desc:      "three way star",
+			input:     "y ~ a*b*c",
+			response:  "y",
+			terms:     []Term{{"a"}, {"b"}, {"c"}, {"a", "b"}, {"a", "c"}, {"b", "c"}, {"a", "b", "c"}},
+			intercept: true,
+		},
+		{
+			desc:      "interactions distribute over sums",
+			input:     "y ~ (a + b):c",
+			response:  "y",
+			terms:     []Term{{"a", "c"}, {"b", "c"}},
+			intercept: true,
+		},
+		{
+			desc:      "interactions are ordered after main effects",
+			input:     "y ~ a:b + c",
+			response:  "y",
+			terms:     []Term{{"c"}, {"a", "b"}},
+			intercept: true,
+		},
+		{
+			desc:      "interactions are the same in any order",
+			input:     "y ~ a:b + b:a + a:a",
+			response:  "y",
+			terms:     []Term{{"a"}, {"a", "b"}},
+			intercept: true,
+		},
+		{
+			desc:      "removing an interaction",
+			input:     "y ~ a*b - b:a",
+			response:  "y",
+			terms:     []Term{{"a"}, {"b"}},
+			intercept: true,
+		},
+		{
+			desc:      "intercept only",
+			input:     "y ~ 1",
+			response:  "y",
+			terms:     nil,
+			intercept: true,
+		},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got, err := Parse(test_case.input)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got.Response != test_case.response {
+				t.Errorf("expected response %s, got %s", test_case.response, got.Response)
+			}
+			if !reflect.DeepEqual(got.Terms, test_case.terms) {
+				t.Errorf("expected to be the same, got %v, want %v", got.Terms, test_case.terms)
+			}
+			if got.Intercept != test_case.intercept {
+				t.Errorf("expected intercept %v, got %v", test_case.intercept, got.Intercept)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	type TestCase struct {
+		desc  string
+		input string
+		pos   int
+	}
+
+	test_cases := []TestCase{
+		{desc: "missing response", input: "~ x1", pos: 0},
+		{desc: "missing tilde", input: "y x1", pos: 2},
+		{desc: "trailing operator", input: "y ~ x1 +", pos: 8},
+		{desc: "doubled operator", input: "y ~ x1 + + x2", pos: 9},
+		{desc: "unbalanced parentheses", input: "y ~ (x1 + x2", pos: 12},
+		{desc: "unexpected closing parenthesis", input: "y ~ x1)", pos: 6},
+		{desc: "invalid number", input: "y ~ x1 + 2", pos: 9},
+		{desc: "response on both sides", input: "y ~ x1 + y", pos: 9},
+		{desc: "dangling interaction", input: "y ~ x1:", pos: 7},
+		{desc: "unknown function", input: "y ~ log(x1)", pos: 4},
+		{desc: "factor of an expression", input: "y ~ factor(x1 + x2)", pos: 14},
+		{desc: "factor with too many arguments", input: "y ~ factor(x1, x2)", pos: 4},
+		{desc: "interaction with the intercept", input: "y ~ x1:1", pos: 6},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			_, err := Parse(test_case.input)
+
+			var ferr *Error
+			if !errors.As(err, &ferr) {
+				t.Fatalf("expected a formula error, got %v", err)
+			}
+			if ferr.Pos != test_case.pos {
+				t.Errorf("expected error at %d, got %d (%s)", test_case.pos, ferr.Pos, ferr)
+			}
+		})
+	}
+}
+
+func TestParseFactor(t *testing.T) {
+	f, err := Parse("y ~ x1 + factor(g):x1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := []Term{{"x1"}, {"g", "x1"}}; !reflect.DeepEqual(f.Terms, want) {
+		t.Errorf("expected to be the same, got %v, want %v", f.Terms, want)
+	}
+	if !f.Factors["g"] || f.Factors["x1"] {
+		t.Errorf("expected only g to be a factor, got %v", f.Factors)
+	}
+	if got, want := f.String(), "y ~ x1 + factor(g):x1"; got != want {
+		t.Errorf("expected to be the same, got %s, want %s", got, want)
+	}
+}
+
+func TestString(t *testing.T) {
+	f, err := Parse("y~x1*(x2)-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := f.String(), "y ~ x1 + x2 + x1:x2 - 1"; got != want {
+		t.Errorf("expected to be the same, got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+package formula
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The kinds of token a formula can be made up of
+type Kind int
+
+const (
+	EOF Kind = iota
+	Ident
+	Number
+	Tilde
+	Plus
+	Minus
+	Colon
+	Star
+	LParen
+	RParen
+	Comma
+)
+
+func (k Kind) String() string {
+	switch k {
+	case EOF:
+		return "end of formula"
+	case Ident:
+		return "variable"
+	case Number:
+		return "number"
+	case Tilde:
+		return "'~'"
+	case Plus:
+		return "'+'"
+	case Minus:
+		return "'-'"
+	case Colon:
+		return "':'"
+	case Star:
+		return "'*'"
+	case LParen:
+		return "'('"
+	case RParen:
+		return "')'"
+	case Comma:
+		return "','"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// A single lexical token, `Pos` is the byte offset into the formula
+type Token struct {
+	Kind Kind
+	Text string
+	Pos  int
+}
+
+func (t Token) String() string {
+	switch t.Kind {
+	case Ident, Number:
+		return fmt.Sprintf("%s '%s'", t.Kind, t.Text)
+	}
+	return t.Kind.String()
+}
+
+// An error in a formula, tied to the position it occured at
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("formula: column %d: %s", e.Pos+1, e.Msg)
+}
+
+func errorf(pos int, format string, a ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+var punctuation = map[rune]Kind{
+	'~': Tilde,
+	'+': Plus,
+	'-': Minus,
+	':': Colon,
+	'*': Star,
+	'(': LParen,
+	')': RParen,
+	',': Comma,
+}
+
+// Split a formula into its tokens, the last token is always EOF
+func Lex(src string) ([]Token, error) {
+	var tokens []Token
+
+	i := 0
+	for i < len(src) {
+		r, w := utf8.DecodeRuneInString(src[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += w
+		case r == '`': // Backquoted names allow for spaces etc... as in R
+			end := i + 1
+			for end < len(src) && src[end] != '`' {
+				end++
+			}
+			if end >= len(src) {
+				return nil, errorf(i, "unterminated quoted name")
+			}
+			if end == i+1 {
+				return nil, errorf(i, "empty quoted name")
+			}
+			tokens = append(tokens, Token{Kind: Ident, Text: src[i+1 : end], Pos: i})
+			i = end + 1
+		case isIdentStart(r):
+			start := i
+			for i < len(src) {
+				r, w := utf8.DecodeRuneInString(src[i:])
+				if !isIdentPart(r) {
+					break
+				}
+				i += w
+			}
+			tokens = append(tokens, Token{Kind: Ident, Text: src[start:i], Pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, Token{Kind: Number, Text: src[start:i], Pos: start})
+		default:
+			kind, ok := punctuation[r]
+			if !ok {
+				return nil, errorf(i, "unexpected character '%c'", r)
+			}
+			tokens = append(tokens, Token{Kind: kind, Text: string(r), Pos: i})
+			i += w
+		}
+	}
+
+	return append(tokens, Token{Kind: EOF, Pos: len(src)}), nil
+}
+
+// Names start with a letter, dot or underscore, then may also contain digits
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '.' || r == '_'
+}
+
+func isIdentPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_'
+}
@@ -0,0 +1,174 @@
+package formula
+
+type parser struct {
+	tokens []Token
+	i      int
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() Token {
+	t := p.tokens[p.i]
+	if t.Kind != EOF { // Never run off the end
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) expect(k Kind) (Token, error) {
+	t := p.next()
+	if t.Kind != k {
+		return t, errorf(t.Pos, "expected %s, got %s", k, t)
+	}
+	return t, nil
+}
+
+// Parse the tokens of a whole formula, `response ~ expression`
+func (p *parser) parseFormula() (Node, Node, error) {
+	if t := p.peek(); t.Kind == Tilde {
+		return nil, nil, errorf(t.Pos, "missing response before '~'")
+	}
+	lhs, err := p.parsePrimary()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := lhs.(*Var); !ok {
+		return nil, nil, errorf(lhs.Pos(), "response must be a single variable, got '%s'", lhs)
+	}
+
+	if _, err := p.expect(Tilde); err != nil {
+		return nil, nil, err
+	}
+
+	rhs, err := p.parseSum()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if t := p.peek(); t.Kind != EOF {
+		return nil, nil, errorf(t.Pos, "unexpected %s", t)
+	}
+	return lhs, rhs, nil
+}
+
+// sum := ['+' | '-'] product {('+' | '-') product}
+func (p *parser) parseSum() (Node, error) {
+	var x Node
+	if t := p.peek(); t.Kind == Plus || t.Kind == Minus {
+		p.next()
+		operand, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		x = &Unary{Op: t.Kind, X: operand, At: t.Pos}
+	} else {
+		var err error
+		x, err = p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		t := p.peek()
+		if t.Kind != Plus && t.Kind != Minus {
+			return x, nil
+		}
+		p.next()
+		y, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: t.Kind, L: x, R: y, At: t.Pos}
+	}
+}
+
+// product := interaction {'*' interaction}
+func (p *parser) parseProduct() (Node, error) {
+	return p.parseLeft(Star, p.parseInteraction)
+}
+
+// interaction := primary {':' primary}
+func (p *parser) parseInteraction() (Node, error) {
+	return p.parseLeft(Colon, p.parsePrimary)
+}
+
+// Parse a left associative chain of operator `op`
+func (p *parser) parseLeft(op Kind, operand func() (Node, error)) (Node, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.Kind != op {
+			return x, nil
+		}
+		p.next()
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: op, L: x, R: y, At: t.Pos}
+	}
+}
+
+// primary := name | name '(' sum {',' sum} ')' | number | '(' sum ')'
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.Kind {
+	case Ident:
+		if p.peek().Kind == LParen {
+			return p.parseCall(t)
+		}
+		return &Var{Name: t.Text, At: t.Pos}, nil
+	case Number:
+		return &Num{Text: t.Text, At: t.Pos}, nil
+	case LParen:
+		x, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(RParen); err != nil {
+			return nil, err
+		}
+		return &Paren{X: x, At: t.Pos}, nil
+	}
+	return nil, errorf(t.Pos, "expected variable, got %s", t)
+}
+
+// Parse the arguments of a call to function `name`
+func (p *parser) parseCall(name Token) (Node, error) {
+	p.next() // '('
+	call := &Call{Func: name.Text, At: name.Pos}
+	for {
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+
+		t := p.next()
+		if t.Kind == RParen {
+			break
+		}
+		if t.Kind != Comma {
+			return nil, errorf(t.Pos, "expected ',' or ')', got %s", t)
+		}
+	}
+
+	switch call.Func {
+	case "factor":
+		if len(call.Args) != 1 {
+			return nil, errorf(call.At, "factor takes exactly one argument, got %d", len(call.Args))
+		}
+		if _, ok := call.Args[0].(*Var); !ok {
+			return nil, errorf(call.Args[0].Pos(), "factor expects a variable, got '%s'", call.Args[0])
+		}
+	default:
+		return nil, errorf(call.At, "unknown function '%s'", call.Func)
+	}
+	return call, nil
+}
@@ -0,0 +1,341 @@
+package lm
+
+import (
+	"fmt"
+	"ols/formula"
+	"ols/matrix"
+	"slices"
+	"strconv"
+)
+
+// A record column used in the model, either numeric or a factor
+type variable struct {
+	name   string
+	col    int
+	values []float64 // Parsed values if numeric
+	levels []string  // Levels if a factor, the reference level first
+}
+
+func (v *variable) isFactor() bool {
+	return v.levels != nil
+}
+
+// Read variable `name` from the records, parsing it as a number unless it is
+// declared as a factor or contains something that isn't a number. Missing
+// (blank) values are an error, OmitMissing drops their rows first.
+func newVariable(records [][]string, name string, col int, factor bool) (*variable, error) {
+	v := &variable{name: name, col: col}
+	for i, row := range records[1:] {
+		if row[col] == "" {
+			return nil, fmt.Errorf("missing value for '%s' on row %d", name, i+1)
+		}
+	}
+
+	if !factor {
+		v.values = make([]float64, len(records)-1)
+		for i, row := range records[1:] {
+			val, err := strconv.ParseFloat(row[col], 64)
+			if err != nil {
+				factor = true
+				break
+			}
+			v.values[i] = val
+		}
+	}
+
+	if factor {
+		v.values = nil
+		v.levels = levels(records, col)
+	}
+	return v, nil
+}
+
+// The sorted unique values in column `col`. Like R, levels which are all
+// numbers are sorted numerically, otherwise alphabetically.
+func levels(records [][]string, col int) []string {
+	seen := make(map[string]bool)
+	levels := []string{}
+	numeric := true
+	for _, row := range records[1:] {
+		v := row[col]
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		levels = append(levels, v)
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			numeric = false
+		}
+	}
+
+	if numeric {
+		slices.SortFunc(levels, func(a, b string) int {
+			x, _ := strconv.ParseFloat(a, 64)
+			y, _ := strconv.ParseFloat(b, 64)
+			if x < y {
+				return -1
+			} else if x > y {
+				return 1
+			}
+			return 0
+		})
+	} else {
+		slices.Sort(levels)
+	}
+	return levels
+}
+
+// Move level `ref` to the front so it becomes the reference level
+func (v *variable) setReference(ref string) error {
+	if !v.isFactor() {
+		return fmt.Errorf("reference level given for '%s', which is not a factor", v.name)
+	}
+	i := slices.Index(v.levels, ref)
+	if i < 0 {
+		return fmt.Errorf("'%s' is not a level of '%s', expected one of %v", ref, v.name, v.levels)
+	}
+	v.levels = slices.Insert(slices.Delete(v.levels, i, i+1), 0, ref)
+	return nil
+}
+
+// One piece of a design matrix column, a numeric variable or one level of a factor
+type part struct {
+	v     *variable
+	level string
+}
+
+// A column of the design matrix is the product of its parts
+type column struct {
+	name  string
+	parts []part
+}
+
+func (c column) value(records [][]string, i int) float64 {
+	val := 1.0
+	for _, p := range c.parts {
+		if p.v.isFactor() {
+			if records[i+1][p.v.col] != p.level {
+				return 0
+			}
+		} else {
+			val *= p.v.values[i]
+		}
+	}
+	return val
+}
+
+// The columns for a single term. Numeric variables give one column and factors
+// give an indicator column for every level but the reference (treatment
+// coding), interactions are every combination of these. Factors with `full` set
+// get a column for every level.
+func termColumns(vars []*variable, full []bool) []column {
+	cols := []column{{}}
+	for k, v := range vars {
+		var next []column
+		for _, c := range cols {
+			if !v.isFactor() {
+				next = append(next, column{
+					name:  joinName(c.name, v.name),
+					parts: append(slices.Clone(c.parts), part{v: v}),
+				})
+				continue
+			}
+			levels := v.levels[1:]
+			if full[k] {
+				levels = v.levels
+			}
+			for _, level := range levels {
+				next = append(next, column{
+					name:  joinName(c.name, v.name+level),
+					parts: append(slices.Clone(c.parts), part{v: v, level: level}),
+				})
+			}
+		}
+		cols = next
+	}
+	return cols
+}
+
+// Whether `term` without its `k`th variable is contained in one of the earlier
+// terms, the empty term (the intercept) always is. As in R, a factor is only
+// treatment coded when this margin is in the model, as otherwise dropping its
+// reference level would force that level's effect to be zero.
+func hasMargin(earlier []formula.Term, term formula.Term, k int) bool {
+	if len(term) == 1 {
+		return true
+	}
+	for _, t := range earlier {
+		contained := true
+		for j, name := range term {
+			if j != k && !slices.Contains(t, name) {
+				contained = false
+				break
+			}
+		}
+		if contained {
+			return true
+		}
+	}
+	return false
+}
+
+func joinName(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + ":" + b
+}
+
+// Returns the records without the rows which are missing (blank) a value of
+// any variable in formula `f`, and how many were dropped, like R's na.omit.
+// Variables which aren't in the records are left for Design to report.
+func OmitMissing(records [][]string, f *formula.Formula) ([][]string, int) {
+	if len(records) < 1 {
+		return records, 0
+	}
+	names := []string{f.Response}
+	for _, term := range f.Terms {
+		names = append(names, term...)
+	}
+	var cols []int
+	for _, name := range names {
+		if col := slices.Index(records[0], name); col >= 0 {
+			cols = append(cols, col)
+		}
+	}
+
+	z := [][]string{records[0]}
+	for _, row := range records[1:] {
+		complete := true
+		for _, col := range cols {
+			if col < len(row) && row[col] == "" {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			z = append(z, row)
+		}
+	}
+	return z, len(records) - len(z)
+}
+
+// Below this proportion of non-zeros the design matrix is stored as CSR
+const sparseDesign = 0.1
+
+// Build the response `y` and design matrix `X` for formula `f`, returning the
+// names of the columns of `X`. `refs` maps factors to their reference level.
+func Design(records [][]string, f *formula.Formula, refs map[string]string) (matrix.Vector, matrix.Matrix, []string, error) {
+	// For assume:
+	//  - the first row is names
+	names := records[0]
+	cols := make(map[string]int, len(names))
+	for i, name := range names {
+		cols[name] = i
+	}
+	for i, row := range records[1:] {
+		if len(row) != len(names) {
+			return nil, nil, nil, fmt.Errorf("row %d has %d columns, expected %d", i+1, len(row), len(names))
+		}
+	}
+
+	vars := make(map[string]*variable)
+	lookup := func(name string) (*variable, error) {
+		if v, ok := vars[name]; ok {
+			return v, nil
+		}
+		col, ok := cols[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable '%s'", name)
+		}
+		v, err := newVariable(records, name, col, f.Factors[name])
+		if err != nil {
+			return nil, err
+		}
+		vars[name] = v
+		return v, nil
+	}
+
+	dep, err := lookup(f.Response)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if dep.isFactor() {
+		return nil, nil, nil, fmt.Errorf("response '%s' is not numeric", f.Response)
+	}
+
+	var terms [][]*variable
+	for _, term := range f.Terms {
+		var tv []*variable
+		for _, name := range term {
+			v, err := lookup(name)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			tv = append(tv, v)
+		}
+		terms = append(terms, tv)
+	}
+
+	for name, ref := range refs {
+		v, ok := vars[name]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("reference level given for '%s', which is not in the model", name)
+		}
+		if err := v.setReference(ref); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// Column 0 of X is the intercept, if there is one
+	var design []column
+	if f.Intercept {
+		design = append(design, column{name: "(Intercept)"})
+	}
+	// Without an intercept the first factor can't be collinear with it, so like R
+	// it keeps all of its levels
+	first := !f.Intercept
+	for i, tv := range terms {
+		full := make([]bool, len(tv))
+		for k, v := range tv {
+			if !v.isFactor() {
+				continue
+			}
+			full[k] = first || !hasMargin(f.Terms[:i], f.Terms[i], k)
+			first = false
+		}
+		design = append(design, termColumns(tv, full)...)
+	}
+
+	n := len(records) - 1
+	y := matrix.NewVector(n)
+	var triplets []matrix.Triplet
+	for i := 0; i < n; i++ {
+		y[i] = dep.values[i]
+		for j, c := range design {
+			if v := c.value(records, i); v != 0 {
+				triplets = append(triplets, matrix.Triplet{I: i, J: j, V: v})
+			}
+		}
+	}
+
+	// Dummy columns of factors with many levels are mostly zeros, so keep X compressed
+	var X matrix.Matrix
+	if float64(len(triplets)) < sparseDesign*float64(n*len(design)) {
+		X, err = matrix.NewCSR(n, len(design), triplets)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	} else {
+		X = matrix.Zero(n, len(design))
+		for _, t := range triplets {
+			X.Set(t.I, t.J, t.V)
+		}
+	}
+
+	ind := make([]string, len(design))
+	for j, c := range design {
+		ind[j] = c.name
+	}
+	return y, X, ind, nil
+}
@@ -0,0 +1,262 @@
+/*
+Package lm fits linear models by ordinary least squares.
+
+	mod, err := lm.Fit(lm.CSVFile("input.csv"), "y ~ x1 + x2 + x3")
+	if err != nil {
+		return err
+	}
+	fmt.Println(mod.Names(), mod.Coefficients())
+*/
+package lm
+
+import (
+	"fmt"
+	"math"
+	"ols/formula"
+	"ols/matrix"
+)
+
+// A fitted linear model
+type Model struct {
+	formula      *formula.Formula
+	names        []string
+	y            matrix.Vector
+	x            matrix.Matrix
+	rank         int
+	iterations   int
+	omitted      int
+	inestimable  []bool // Coefficients the minimum norm solution can't determine
+	cond, limit  float64
+	qr           matrix.QR
+	xTx_inv      matrix.Matrix
+	fitted, coef matrix.Vector
+}
+
+// Settings for a fit, changed by passing `Option`s to `Fit`
+type config struct {
+	refs      map[string]string
+	solver    Solver
+	iterative matrix.IterativeOptions
+	limit     float64
+	strict    bool
+}
+
+type Option func(*config)
+
+// Use `level` as the reference level of factor `name`, rather than the first level
+func WithReference(name, level string) Option {
+	return func(c *config) {
+		if c.refs == nil {
+			c.refs = make(map[string]string)
+		}
+		c.refs[name] = level
+	}
+}
+
+// The condition number of the design matrix past which a fit is ill-conditioned.
+// X'X has the square of it, and beyond 1e16 has no accurate digits left.
+const DefaultMaxCondition = 1e8
+
+// Treat the fit as ill-conditioned when the condition number of the design
+// matrix is over `limit`, rather than DefaultMaxCondition
+func WithMaxCondition(limit float64) Option {
+	return func(c *config) {
+		c.limit = limit
+	}
+}
+
+// Fail to fit an ill-conditioned model, rather than only noting it in the summary
+func WithStrict() Option {
+	return func(c *config) {
+		c.strict = true
+	}
+}
+
+// Fit the model described by formula `f`, i.e. `y ~ x1 + x2`, to the data in `src`
+func Fit(src Source, f string, opts ...Option) (*Model, error) {
+	parsed, err := formula.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	return FitFormula(src, parsed, opts...)
+}
+
+// Fit the model described by an already parsed formula to the data in `src`
+func FitFormula(src Source, f *formula.Formula, opts ...Option) (*Model, error) {
+	c := config{limit: DefaultMaxCondition}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	records, err := src.Records()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 1 {
+		return nil, fmt.Errorf("no data, expected a row of names")
+	}
+
+	// Like R, rows missing any of the model's variables are left out
+	records, omitted := OmitMissing(records, f)
+	y, X, names, err := Design(records, f, c.refs)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Model{
+		formula: f,
+		names:   names,
+		y:       y,
+		x:       X,
+		omitted: omitted,
+		limit:   c.limit,
+	}
+	switch c.solver {
+	case SolverQR:
+		err = m.solveQR()
+	case SolverCholesky:
+		err = m.solveCholesky()
+	case SolverSVD:
+		err = m.solveMinNorm()
+	case SolverLSQR:
+		err = m.solveLSQR(c.iterative)
+	default:
+		err = fmt.Errorf("unknown solver %v", c.solver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.strict && m.IllConditioned() {
+		return nil, fmt.Errorf("design matrix is ill-conditioned, condition number %.3g is over %.3g", m.cond, m.limit)
+	}
+
+	// Xβ directly, rather than through the n x n hat matrix. Aliased
+	// coefficients contribute nothing.
+	beta := m.coef.Copy()
+	for j, b := range beta {
+		if math.IsNaN(b) {
+			beta[j] = 0
+		}
+	}
+	m.fitted, err = matrix.MultiplyVector(X, beta)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// The formula the model was fitted with
+func (m *Model) Formula() *formula.Formula {
+	return m.formula
+}
+
+// The name of the response variable
+func (m *Model) Response() string {
+	return m.formula.Response
+}
+
+// The names of the coefficients, i.e. the columns of the design matrix
+func (m *Model) Names() []string {
+	return append([]string(nil), m.names...)
+}
+
+// The estimated coefficients, in the same order as `Names`. Aliased
+// coefficients are NaN.
+func (m *Model) Coefficients() []float64 {
+	return m.coef.Copy()
+}
+
+// The rank of the design matrix, the number of coefficients which can be estimated
+func (m *Model) Rank() int {
+	return m.rank
+}
+
+// Whether the columns of the design matrix are collinear. Either some of the
+// coefficients are aliased, or with SolverSVD they are the minimum norm
+// solution, one of many which fit equally well.
+func (m *Model) RankDeficient() bool {
+	return m.rank < len(m.names)
+}
+
+// The names of the coefficients which were dropped from the fit, because their
+// columns are linear combinations of earlier columns
+func (m *Model) Aliased() []string {
+	var z []string
+	for j, name := range m.names {
+		if math.IsNaN(m.coef[j]) {
+			z = append(z, name)
+		}
+	}
+	return z
+}
+
+// The number of iterations SolverLSQR took to converge, 0 for the other solvers
+func (m *Model) Iterations() int {
+	return m.iterations
+}
+
+// The number of rows left out of the fit because they were missing a value
+func (m *Model) Omitted() int {
+	return m.omitted
+}
+
+// An estimate of the condition number of the design matrix, of the columns
+// which weren't aliased. This is the 1-norm condition number of R from the QR
+// decomposition, whichever solver was used. Large values mean the columns are
+// close to collinear, or on very different scales, and the estimates may be
+// inaccurate.
+func (m *Model) Cond() float64 {
+	return m.cond
+}
+
+// Whether the condition number of the design matrix is over the limit,
+// DefaultMaxCondition unless set with WithMaxCondition
+func (m *Model) IllConditioned() bool {
+	return m.cond > m.limit
+}
+
+// The fitted values for each row of the data
+func (m *Model) Fitted() []float64 {
+	return m.fitted.Copy()
+}
+
+// The residuals (response - fitted) for each row of the data
+func (m *Model) Residuals() []float64 {
+	e, _ := m.y.Sub(m.fitted) // The same length, both are one per row
+	return e
+}
+
+// The leverage of each row, the diagonal of the hat matrix X (X'X)^-1 X'.
+// As H = QQ' this is the squared norm of each row of Q, so the n x n hat
+// matrix itself is never formed.
+func (m *Model) Leverage() []float64 {
+	if m.qr.N == 0 {
+		return m.leverageNormal() // Not fitted by QR
+	}
+
+	q := m.qr.Q()
+	n, p := q.Dims()
+	h := make([]float64, n)
+	for i := range h {
+		for j := 0; j < p; j++ {
+			h[i] += q.At(i, j) * q.At(i, j)
+		}
+	}
+	return h
+}
+
+// Without Q the leverage of row i is x_i (X'X)^-1 x_i', or with the
+// pseudo-inverse of X'X if X is rank deficient
+func (m *Model) leverageNormal() []float64 {
+	n, _ := m.x.Dims()
+	h := make([]float64, n)
+	xA, err := matrix.Multiply(m.x, m.xTx_inv)
+	if err != nil {
+		return h // Can't happen, X'X has as many rows as X has columns
+	}
+	m.x.NonZeros(func(i, j int, v float64) {
+		h[i] += v * xA.At(i, j)
+	})
+	return h
+}
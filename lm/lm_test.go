@@ -0,0 +1,668 @@
+package lm
+
+import (
+	"fmt"
+	"math"
+	"ols/formula"
+	"ols/matrix"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Helper to compare slices of floats up to a tolerance
+func near(got, want []float64, tol float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+// y = 1, 3, 2, 5, 4 on x = 1..5 has slope 0.8 and intercept 0.6
+var simple = Records{
+	{"y", "x"},
+	{"1", "1"},
+	{"3", "2"},
+	{"2", "3"},
+	{"5", "4"},
+	{"4", "5"},
+}
+
+func TestFit(t *testing.T) {
+	t.Run("simple linear regression", func(t *testing.T) {
+		mod, err := Fit(simple, "y ~ x")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if got, want := mod.Names(), []string{"(Intercept)", "x"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got, want := mod.Coefficients(), []float64{0.6, 0.8}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got, want := mod.Fitted(), []float64{1.4, 2.2, 3.0, 3.8, 4.6}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got, want := mod.Residuals(), []float64{-0.4, 0.8, -1.0, 1.2, -0.6}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("leverage", func(t *testing.T) {
+		mod, err := Fit(simple, "y ~ x")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		// 1/n + (x - mean(x))^2 / Sxx
+		if got, want := mod.Leverage(), []float64{0.6, 0.3, 0.2, 0.3, 0.6}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("exact fit with an interaction", func(t *testing.T) {
+		// y = 1 + 2 a - b + 0.5 a b
+		records := Records{{"y", "a", "b"}}
+		for _, ab := range [][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {2, 3}, {3, 1}, {-1, 2}} {
+			a, b := ab[0], ab[1]
+			records = append(records, []string{
+				ftoa(1 + 2*a - b + 0.5*a*b), ftoa(a), ftoa(b),
+			})
+		}
+		mod, err := Fit(records, "y ~ a*b")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got, want := mod.Names(), []string{"(Intercept)", "a", "b", "a:b"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got, want := mod.Coefficients(), []float64{1, 2, -1, 0.5}; !near(got, want, 1e-10) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("fail on a bad formula", func(t *testing.T) {
+		if _, err := Fit(simple, "y ~ "); err == nil {
+			t.Errorf("expected fit to fail")
+		}
+	})
+
+	t.Run("fail on an unknown variable", func(t *testing.T) {
+		if _, err := Fit(simple, "y ~ z"); err == nil {
+			t.Errorf("expected fit to fail")
+		}
+	})
+}
+
+// The simple data with b = 2x
+var collinear = Records{
+	{"y", "x", "b"},
+	{"1", "1", "2"},
+	{"3", "2", "4"},
+	{"2", "3", "6"},
+	{"5", "4", "8"},
+	{"4", "5", "10"},
+}
+
+func TestSolvers(t *testing.T) {
+	t.Run("cholesky agrees with QR", func(t *testing.T) {
+		mod, err := Fit(simple, "y ~ x", WithSolver(SolverCholesky))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got, want := mod.Coefficients(), []float64{0.6, 0.8}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got, want := mod.Leverage(), []float64{0.6, 0.3, 0.2, 0.3, 0.6}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+
+		qr, _ := Fit(simple, "y ~ x")
+		got, _ := mod.Summary()
+		want, _ := qr.Summary()
+		if got.String() != want.String() {
+			t.Errorf("expected to be the same, got\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("collinear columns are aliased", func(t *testing.T) {
+		for _, solver := range []Solver{SolverQR, SolverCholesky} {
+			mod, err := Fit(collinear, "y ~ x + b", WithSolver(solver))
+			if err != nil {
+				t.Fatalf("%v: unexpected error: %s", solver, err)
+			}
+			if !mod.RankDeficient() || mod.Rank() != 2 {
+				t.Errorf("%v: expected rank 2 of 3, got %d", solver, mod.Rank())
+			}
+			if got, want := mod.Aliased(), []string{"b"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("%v: expected to be the same, got %v, want %v", solver, got, want)
+			}
+			got := mod.Coefficients()
+			if !near(got[:2], []float64{0.6, 0.8}, 1e-12) || !math.IsNaN(got[2]) {
+				t.Errorf("%v: expected to be the same, got %v, want [0.6 0.8 NaN]", solver, got)
+			}
+			if got, want := mod.Fitted(), []float64{1.4, 2.2, 3.0, 3.8, 4.6}; !near(got, want, 1e-12) {
+				t.Errorf("%v: expected to be the same, got %v, want %v", solver, got, want)
+			}
+			if got, want := mod.Leverage(), []float64{0.6, 0.3, 0.2, 0.3, 0.6}; !near(got, want, 1e-12) {
+				t.Errorf("%v: expected to be the same, got %v, want %v", solver, got, want)
+			}
+
+			s, err := mod.Summary()
+			if err != nil {
+				t.Fatalf("%v: unexpected error: %s", solver, err)
+			}
+			if s.DF != 3 || s.FDF1 != 1 {
+				t.Errorf("%v: expected 1 and 3 degrees of freedom, got %d and %d", solver, s.FDF1, s.DF)
+			}
+			if !s.Coefficients[2].Aliased {
+				t.Errorf("%v: expected b to be aliased", solver)
+			}
+			for _, want := range []string{"(1 not defined because of singularities)", "aliased with earlier terms: b"} {
+				if !strings.Contains(s.String(), want) {
+					t.Errorf("%v: expected %q in\n%s", solver, want, s)
+				}
+			}
+		}
+	})
+
+	t.Run("the later of the collinear columns is aliased", func(t *testing.T) {
+		mod, err := Fit(collinear, "y ~ b + x")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got, want := mod.Aliased(), []string{"x"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got := mod.Coefficients(); !near(got[:2], []float64{0.6, 0.4}, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want [0.6 0.4 NaN]", got)
+		}
+	})
+
+	t.Run("the dummy variable trap", func(t *testing.T) {
+		// An indicator for each level of g alongside the intercept
+		records := Records{{"y", "g", "isa", "isb"}}
+		for i, g := range []string{"a", "b", "a", "b", "a", "b"} {
+			isa, isb := "0", "1"
+			if g == "a" {
+				isa, isb = "1", "0"
+			}
+			records = append(records, []string{ftoa(float64(i)), g, isa, isb})
+		}
+		mod, err := Fit(records, "y ~ isa + isb")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got, want := mod.Aliased(), []string{"isb"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("svd gives the minimum norm solution", func(t *testing.T) {
+		// b = 2x, so the slope of 0.8 is shared as c_x + 2 c_b = 0.8 and the
+		// smallest such coefficients are 0.16 and 0.32
+		mod, err := Fit(collinear, "y ~ x + b", WithSolver(SolverSVD))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !mod.RankDeficient() || mod.Rank() != 2 || len(mod.Aliased()) != 0 {
+			t.Errorf("expected rank 2 of 3 without aliasing, got %d and %v", mod.Rank(), mod.Aliased())
+		}
+		if got, want := mod.Coefficients(), []float64{0.6, 0.16, 0.32}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got, want := mod.Leverage(), []float64{0.6, 0.3, 0.2, 0.3, 0.6}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		s, _ := mod.Summary()
+		if !strings.Contains(s.String(), "rank 2 for 3 coefficients") {
+			t.Errorf("expected a note about the rank, got\n%s", s)
+		}
+
+		// Only c_x + 2 c_b is determined, the intercept still is
+		for j, want := range []bool{false, true, true} {
+			c := s.Coefficients[j]
+			if c.Inestimable != want || math.IsNaN(c.P) != want {
+				t.Errorf("%s: expected inestimable %v, got %v with p-value %v", c.Name, want, c.Inestimable, c.P)
+			}
+		}
+		if !strings.Contains(s.String(), "Note: not estimable, only combinations with collinear terms are: x, b") {
+			t.Errorf("expected a note about the inestimable coefficients, got\n%s", s)
+		}
+	})
+
+	t.Run("lsqr agrees with QR", func(t *testing.T) {
+		mod, err := Fit(simple, "y ~ x", WithSolver(SolverLSQR))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got, want := mod.Coefficients(), []float64{0.6, 0.8}; !near(got, want, 1e-9) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got, want := mod.Leverage(), []float64{0.6, 0.3, 0.2, 0.3, 0.6}; !near(got, want, 1e-9) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if mod.Iterations() == 0 {
+			t.Errorf("expected the iterations to be reported")
+		}
+
+		qr, _ := Fit(simple, "y ~ x")
+		got, _ := mod.Summary()
+		want, _ := qr.Summary()
+		if got.String() != want.String() {
+			t.Errorf("expected to be the same, got\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("lsqr reports not converging", func(t *testing.T) {
+		_, err := Fit(CSVFile("testdata/longley.csv"), "y ~ x1 + x2 + x3 + x4 + x5 + x6", WithSolver(SolverLSQR), WithIterativeOptions(matrix.IterativeOptions{MaxIterations: 2}))
+		if err == nil || !strings.Contains(err.Error(), "did not converge") {
+			t.Errorf("expected a convergence error, got %v", err)
+		}
+	})
+
+	t.Run("fail on an unknown solver", func(t *testing.T) {
+		if _, err := Fit(simple, "y ~ x", WithSolver(Solver(-1))); err == nil {
+			t.Errorf("expected fit to fail")
+		}
+	})
+}
+
+func TestSummary(t *testing.T) {
+	mod, err := Fit(simple, "y ~ x")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err := mod.Summary()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// RSS = 3.6 on 3 df, Sxx = 10, Syy = 10
+	sigma := math.Sqrt(1.2)
+	if !near([]float64{s.Sigma}, []float64{sigma}, 1e-12) || s.DF != 3 {
+		t.Errorf("got sigma %v on %d df, want %v on 3", s.Sigma, s.DF, sigma)
+	}
+	slope := s.Coefficients[1]
+	if !near([]float64{slope.StdErr, slope.T}, []float64{sigma / math.Sqrt(10), 0.8 / (sigma / math.Sqrt(10))}, 1e-12) {
+		t.Errorf("unexpected slope %+v", slope)
+	}
+	if !near([]float64{s.RSquared, s.AdjRSquared}, []float64{0.64, 0.52}, 1e-12) {
+		t.Errorf("got R-squared %v and %v, want 0.64 and 0.52", s.RSquared, s.AdjRSquared)
+	}
+	// With one regressor F = t^2 and they share a p-value
+	if !near([]float64{s.FStatistic, s.FP}, []float64{slope.T * slope.T, slope.P}, 1e-10) {
+		t.Errorf("got F %v (p = %v), want %v (p = %v)", s.FStatistic, s.FP, slope.T*slope.T, slope.P)
+	}
+	if !strings.Contains(s.String(), "Residual standard error: 1.095 on 3 degrees of freedom") {
+		t.Errorf("unexpected summary\n%s", s)
+	}
+
+	t.Run("an exact fit is noted", func(t *testing.T) {
+		exact := Records{{"y", "x"}, {"3", "1"}, {"5", "2"}, {"7", "3"}, {"9", "4"}}
+		mod, err := Fit(exact, "y ~ x")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s, err := mod.Summary()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !s.PerfectFit || !strings.Contains(s.String(), "Note: essentially perfect fit") {
+			t.Errorf("expected a note about the perfect fit, got\n%s", s)
+		}
+	})
+
+	if s.PerfectFit || strings.Contains(s.String(), "perfect fit") {
+		t.Errorf("expected no note about a perfect fit, got\n%s", s)
+	}
+
+	t.Run("the summary returned with an error can be printed", func(t *testing.T) {
+		mod, err := Fit(Records{{"y", "x"}, {"1", "2"}, {"3", "4"}}, "y ~ x")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s, err := mod.Summary()
+		if err == nil {
+			t.Fatalf("expected an error with no residual degrees of freedom")
+		}
+		if got := s.String(); strings.Contains(got, "Residuals:") {
+			t.Errorf("expected no residuals, got\n%s", got)
+		}
+	})
+}
+
+func TestFactors(t *testing.T) {
+	records := Records{
+		{"y", "g", "n"},
+		{"1", "b", "3"},
+		{"2", "a", "1"},
+		{"3", "c", "2"},
+		{"4", "a", "3"},
+		{"6", "c", "1"},
+	}
+
+	type TestCase struct {
+		desc    string
+		formula string
+		opts    []Option
+		want    []string
+	}
+
+	test_cases := []TestCase{
+		{
+			desc:    "string columns are treatment coded",
+			formula: "y ~ g",
+			want:    []string{"(Intercept)", "gb", "gc"},
+		},
+		{
+			desc:    "the reference level can be changed",
+			formula: "y ~ g",
+			opts:    []Option{WithReference("g", "c")},
+			want:    []string{"(Intercept)", "ga", "gb"},
+		},
+		{
+			desc:    "numeric columns can be declared factors",
+			formula: "y ~ factor(n)",
+			want:    []string{"(Intercept)", "n2", "n3"},
+		},
+		{
+			desc:    "all levels are kept without an intercept",
+			formula: "y ~ g - 1",
+			want:    []string{"ga", "gb", "gc"},
+		},
+		{
+			desc:    "the first factor keeps all levels without an intercept, even after a numeric term",
+			formula: "y ~ n + g - 1",
+			want:    []string{"n", "ga", "gb", "gc"},
+		},
+		{
+			desc:    "an interaction without its main effect keeps all levels",
+			formula: "y ~ n:g",
+			want:    []string{"(Intercept)", "n:ga", "n:gb", "n:gc"},
+		},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			mod, err := Fit(records, test_case.formula, test_case.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := mod.Names(); !reflect.DeepEqual(got, test_case.want) {
+				t.Errorf("expected to be the same, got %v, want %v", got, test_case.want)
+			}
+		})
+	}
+
+	// Too many columns to fit to these records, so only the design
+	t.Run("interactions are coded by the margins in the model", func(t *testing.T) {
+		want := map[string][]string{
+			"y ~ g + g:n": {"(Intercept)", "gb", "gc", "ga:n", "gb:n", "gc:n"},
+			"y ~ g * n":   {"(Intercept)", "gb", "gc", "n", "gb:n", "gc:n"},
+		}
+		for src, names := range want {
+			f, err := formula.Parse(src)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			_, _, got, err := Design(records, f, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, names) {
+				t.Errorf("%s: expected to be the same, got %v, want %v", src, got, names)
+			}
+		}
+	})
+
+	t.Run("group means without an intercept", func(t *testing.T) {
+		mod, err := Fit(records, "y ~ g - 1")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got, want := mod.Coefficients(), []float64{3, 1, 4.5}; !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("factors with many levels are compressed", func(t *testing.T) {
+		many := Records{{"y", "g"}}
+		var want []float64
+		for k := 0; k < 40; k++ {
+			g := fmt.Sprintf("g%02d", k)
+			many = append(many, []string{ftoa(float64(k)), g}, []string{ftoa(float64(k + 1)), g})
+			want = append(want, float64(k)+0.5)
+		}
+		f, _ := formula.Parse("y ~ g - 1")
+
+		_, X, _, err := Design(many, f, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, ok := X.(*matrix.CSR); !ok {
+			t.Errorf("expected a CSR design matrix, got %T", X)
+		}
+
+		mod, err := FitFormula(many, f)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := mod.Coefficients(); !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+
+		mod, err = FitFormula(many, f, WithSolver(SolverLSQR))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := mod.Coefficients(); !near(got, want, 1e-9) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("fail on an unknown reference level", func(t *testing.T) {
+		if _, err := Fit(records, "y ~ g", WithReference("g", "z")); err == nil {
+			t.Errorf("expected fit to fail")
+		}
+	})
+
+	t.Run("fail on a non-numeric response", func(t *testing.T) {
+		if _, err := Fit(records, "g ~ y"); err == nil {
+			t.Errorf("expected fit to fail")
+		}
+	})
+}
+
+func ftoa(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+func TestLongley(t *testing.T) {
+	// The NIST StRD Longley data is notoriously ill-conditioned, these are its certified values
+	mod, err := Fit(CSVFile("testdata/longley.csv"), "y ~ x1 + x2 + x3 + x4 + x5 + x6")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s, err := mod.Summary()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	type certified struct {
+		estimate, stdErr float64
+	}
+	want := []certified{
+		{-3482258.63459582, 890420.383607373},
+		{15.0618722713733, 84.9149257747669},
+		{-0.358191792925910e-01, 0.334910077722432e-01},
+		{-2.02022980381683, 0.488399681651699},
+		{-1.03322686717359, 0.214274163161675},
+		{-0.511041056535807e-01, 0.226073200069370},
+		{1829.15146461355, 455.478499142212},
+	}
+
+	// Agreement to (at least) 9 significant figures
+	relative := func(got, want float64) float64 {
+		return math.Abs(got-want) / math.Abs(want)
+	}
+	for j, c := range s.Coefficients {
+		if relative(c.Estimate, want[j].estimate) > 1e-9 {
+			t.Errorf("%s: got estimate %v, want %v", c.Name, c.Estimate, want[j].estimate)
+		}
+		if relative(c.StdErr, want[j].stdErr) > 1e-9 {
+			t.Errorf("%s: got standard error %v, want %v", c.Name, c.StdErr, want[j].stdErr)
+		}
+	}
+	if relative(s.Sigma, 304.854073561965) > 1e-9 {
+		t.Errorf("got residual standard error %v, want %v", s.Sigma, 304.854073561965)
+	}
+	if relative(s.RSquared, 0.995479004577296) > 1e-9 {
+		t.Errorf("got R-squared %v, want %v", s.RSquared, 0.995479004577296)
+	}
+
+	// LSQR stops at a tolerance rather than solving directly, so agrees to fewer figures
+	t.Run("lsqr", func(t *testing.T) {
+		mod, err := Fit(CSVFile("testdata/longley.csv"), "y ~ x1 + x2 + x3 + x4 + x5 + x6", WithSolver(SolverLSQR))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s, err := mod.Summary()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		for j, c := range s.Coefficients {
+			if relative(c.Estimate, want[j].estimate) > 1e-5 {
+				t.Errorf("%s: got estimate %v, want %v", c.Name, c.Estimate, want[j].estimate)
+			}
+			if relative(c.StdErr, want[j].stdErr) > 1e-5 {
+				t.Errorf("%s: got standard error %v, want %v", c.Name, c.StdErr, want[j].stdErr)
+			}
+		}
+	})
+}
+
+func TestConditionNumber(t *testing.T) {
+	longley := "y ~ x1 + x2 + x3 + x4 + x5 + x6"
+
+	// The 2-norm condition number of the Longley design is 4.86e9, the 1-norm
+	// of its 7 columns is within a factor of 7 of it
+	qr, err := Fit(CSVFile("testdata/longley.csv"), longley)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if qr.Cond() < 4.86e9 || qr.Cond() > 7*4.86e9 {
+		t.Errorf("expected close to 4.86e9, got %v", qr.Cond())
+	}
+
+	// The solvers all use the same definition, so agree
+	for _, solver := range []Solver{SolverQR, SolverCholesky, SolverSVD, SolverLSQR} {
+		t.Run(solver.String(), func(t *testing.T) {
+			mod, err := Fit(CSVFile("testdata/longley.csv"), longley, WithSolver(solver))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if math.Abs(mod.Cond()-qr.Cond()) > 1e-6*qr.Cond() {
+				t.Errorf("expected to be the same, got %v, want %v", mod.Cond(), qr.Cond())
+			}
+			if !mod.IllConditioned() {
+				t.Errorf("expected the Longley design to be ill-conditioned")
+			}
+
+			mod, err = Fit(simple, "y ~ x", WithSolver(solver))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if mod.Cond() < 1 || mod.Cond() > 100 || mod.IllConditioned() {
+				t.Errorf("expected a well conditioned design, got %v", mod.Cond())
+			}
+		})
+	}
+
+	t.Run("summary note", func(t *testing.T) {
+		mod, err := Fit(CSVFile("testdata/longley.csv"), longley)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		s, err := mod.Summary()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !s.IllCond || !strings.Contains(s.String(), "Note: the condition number is large") {
+			t.Errorf("expected a note about the condition number, got\n%s", s)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		_, err := Fit(CSVFile("testdata/longley.csv"), longley, WithStrict())
+		if err == nil || !strings.Contains(err.Error(), "ill-conditioned") {
+			t.Errorf("expected an ill-conditioned error, got %v", err)
+		}
+		if _, err := Fit(CSVFile("testdata/longley.csv"), longley, WithStrict(), WithMaxCondition(1e11)); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if _, err := Fit(simple, "y ~ x", WithStrict()); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+}
+
+func TestMissingValues(t *testing.T) {
+	// Blank numeric and factor cells, and one in a column the model doesn't use
+	records := Records{
+		{"y", "x", "g", "unused"},
+		{"1", "1", "a", "1"},
+		{"3", "2", "b", ""},
+		{"2", "", "a", "1"},
+		{"5", "4", "", "1"},
+		{"4", "5", "b", "1"},
+		{"6", "6", "a", "1"},
+		{"", "7", "b", "1"},
+		{"7", "8", "b", "1"},
+	}
+	complete := Records{
+		{"y", "x", "g", "unused"},
+		{"1", "1", "a", "1"},
+		{"3", "2", "b", ""},
+		{"4", "5", "b", "1"},
+		{"6", "6", "a", "1"},
+		{"7", "8", "b", "1"},
+	}
+
+	mod, err := Fit(records, "y ~ x + g")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want, err := Fit(complete, "y ~ x + g")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !near(mod.Coefficients(), want.Coefficients(), 1e-12) {
+		t.Errorf("expected to be the same, got %v, want %v", mod.Coefficients(), want.Coefficients())
+	}
+	if mod.Omitted() != 3 || len(mod.Fitted()) != 5 {
+		t.Errorf("expected 3 rows omitted and 5 fitted, got %d and %d", mod.Omitted(), len(mod.Fitted()))
+	}
+
+	s, err := mod.Summary()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(s.String(), "(3 observations deleted due to missingness)") {
+		t.Errorf("expected a note about the omitted rows, got\n%s", s)
+	}
+
+	t.Run("the design rejects missing values", func(t *testing.T) {
+		f, _ := formula.Parse("y ~ x + g")
+		_, _, _, err := Design(records, f, nil)
+		if err == nil || !strings.Contains(err.Error(), "missing value for 'y' on row 7") {
+			t.Errorf("expected a missing value error, got %v", err)
+		}
+	})
+}
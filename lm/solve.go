@@ -0,0 +1,278 @@
+package lm
+
+import (
+	"fmt"
+	"math"
+	"ols/matrix"
+)
+
+// A method of solving the least squares problem
+type Solver int
+
+const (
+	// Householder QR of X, accurate even when X is badly conditioned
+	SolverQR Solver = iota
+	// Cholesky factorisation of X'X, faster for tall X but squares its condition number
+	SolverCholesky
+	// Singular value decomposition of X, giving the minimum norm solution when
+	// columns are collinear rather than aliasing them
+	SolverSVD
+	// LSQR iterations using only products with X, for designs too large and
+	// sparse to factorise. Needs full rank for the standard errors.
+	SolverLSQR
+)
+
+func (s Solver) String() string {
+	switch s {
+	case SolverQR:
+		return "qr"
+	case SolverCholesky:
+		return "cholesky"
+	case SolverSVD:
+		return "svd"
+	case SolverLSQR:
+		return "lsqr"
+	}
+	return fmt.Sprintf("Solver(%d)", int(s))
+}
+
+// Returns the solver with the given name, as printed by String
+func ParseSolver(name string) (Solver, error) {
+	for s := SolverQR; s <= SolverLSQR; s++ {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown solver '%s'", name)
+}
+
+// Solve the least squares problem with `s`, rather than QR
+func WithSolver(s Solver) Option {
+	return func(c *config) {
+		c.solver = s
+	}
+}
+
+// Set the tolerance and iteration limit of SolverLSQR
+func WithIterativeOptions(opts matrix.IterativeOptions) Option {
+	return func(c *config) {
+		c.iterative = opts
+	}
+}
+
+// Solve R β = Q'y rather than forming and inverting X'X, which would square
+// the condition number. Like R, columns which are linear combinations of
+// earlier ones are aliased: dropped from the fit and given NaN coefficients.
+func (m *Model) solveQR() error {
+	qr, err := matrix.DecomposePivotedQR(m.x)
+	if err != nil {
+		return err
+	}
+	coef, err := qr.Solve(m.y)
+	if err != nil {
+		return fmt.Errorf("design matrix: %w", err)
+	}
+
+	// (X'X)^-1 = R^-1 R^-T for the standard errors
+	rInv, err := qr.RInverse()
+	if err != nil {
+		return fmt.Errorf("design matrix: %w", err)
+	}
+	xTx_inv, err := matrix.Multiply(rInv, matrix.Transpose(rInv))
+	if err != nil {
+		return err
+	}
+
+	m.qr = qr.QR
+	m.setEstimates(qr.Pivot()[:qr.Rank()], coef, xTx_inv)
+	return m.setCond(qr.R())
+}
+
+// Solve the normal equations X'X β = X'y by Cholesky, X'X is positive definite
+// exactly when X has full rank
+func (m *Model) solveCholesky() error {
+	xT := matrix.Transpose(m.x)
+	xTx, err := matrix.Multiply(xT, m.x)
+	if err != nil {
+		return err
+	}
+	chol, err := matrix.DecomposeCholesky(xTx)
+	if err != nil {
+		return m.solveQR() // X'X is only singular if X is rank deficient
+	}
+
+	xTy, err := matrix.Multiply(xT, m.y)
+	if err != nil {
+		return err
+	}
+	coef, err := chol.Solve(xTy)
+	if err != nil {
+		return err
+	}
+
+	m.setEstimates(allColumns(len(m.names)), coef, chol.Inverse())
+	return m.setCond(matrix.Transpose(chol.L())) // L' is R, up to the signs of its rows
+}
+
+// With collinear columns there are many least squares solutions, so take the
+// one with the smallest norm from the SVD of X. (X'X)^-1 doesn't exist, the
+// pseudo-inverse V S^-2 V' = X^+ X^+' stands in for it.
+func (m *Model) solveMinNorm() error {
+	svd, err := matrix.DecomposeSVD(m.x)
+	if err != nil {
+		return fmt.Errorf("design matrix: %w", err)
+	}
+	coef, err := svd.Solve(m.y)
+	if err != nil {
+		return err
+	}
+	pinv := svd.PseudoInverse()
+	xTx_inv, err := matrix.Multiply(pinv, matrix.Transpose(pinv))
+	if err != nil {
+		return err
+	}
+
+	m.setEstimates(allColumns(len(m.names)), coef, xTx_inv)
+	m.rank = svd.Rank()
+
+	// Coefficient j is estimable when e_j is in the row space of X, spanned by
+	// the first `rank` columns of V. Otherwise it can be traded off against
+	// collinear coefficients, and only the minimum norm makes it unique.
+	v := svd.V()
+	m.inestimable = make([]bool, len(m.names))
+	for j := range m.inestimable {
+		inRowSpace := 0.0
+		for k := 0; k < m.rank; k++ {
+			inRowSpace += v.At(j, k) * v.At(j, k)
+		}
+		m.inestimable[j] = 1-inRowSpace > estimableTolerance
+	}
+
+	// For the same condition number as the other solvers, of the columns QR
+	// wouldn't alias
+	qr, err := matrix.DecomposePivotedQR(m.x)
+	if err != nil {
+		return err
+	}
+	return m.setCond(qr.R())
+}
+
+// Solve by LSQR, which never forms X'X or factorises X. The standard errors
+// need (X'X)^-1, found a column at a time by conjugate gradients on X'X, which
+// stays sparse when X is.
+//
+// Both converge in fewer iterations, and more accurately, when the columns of X
+// are on the same scale, so they are solved for X D whose columns have unit
+// norm. Then β = D β' and (X'X)^-1 = D (D X'X D)^-1 D.
+func (m *Model) solveLSQR(opts matrix.IterativeOptions) error {
+	n, p := m.x.Dims()
+	d := matrix.NewVector(p)
+	m.x.NonZeros(func(i, j int, v float64) {
+		d[j] = math.Hypot(d[j], v)
+	})
+	for j := range d {
+		if d[j] == 0 {
+			d[j] = 1 // A zero column can't be scaled, nor estimated
+		}
+		d[j] = 1 / d[j]
+	}
+	var triplets []matrix.Triplet
+	m.x.NonZeros(func(i, j int, v float64) {
+		triplets = append(triplets, matrix.Triplet{I: i, J: j, V: v * d[j]})
+	})
+	xd, err := matrix.NewCSR(n, p, triplets)
+	if err != nil {
+		return err
+	}
+
+	res, err := matrix.LSQR(xd, m.y, opts)
+	if err != nil {
+		return err
+	}
+	if !res.Converged {
+		return fmt.Errorf("lsqr did not converge in %d iterations, residual %g", res.Iterations, res.Residual)
+	}
+	coef, _ := res.X.MulElem(d) // β = D β
+
+	xTx, err := matrix.Multiply(matrix.Transpose(xd), xd)
+	if err != nil {
+		return err
+	}
+	xTx_inv := matrix.NewDense(p, p)
+	e := matrix.NewDense(p, 1)
+	for j := 0; j < p; j++ {
+		if j > 0 {
+			e.Set(j-1, 0, 0)
+		}
+		e.Set(j, 0, 1)
+		col, err := matrix.ConjugateGradient(xTx, e, opts)
+		if err != nil || !col.Converged {
+			return fmt.Errorf("design matrix: X'X is singular or badly conditioned, use another solver")
+		}
+		for i := 0; i < p; i++ {
+			xTx_inv.Set(i, j, d[i]*col.X[i]*d[j])
+		}
+	}
+
+	m.setEstimates(allColumns(p), coef, xTx_inv)
+	m.iterations = res.Iterations
+
+	// D X'X D = L L', so X D = Q L' and R is L' D^-1
+	chol, err := matrix.DecomposeCholesky(xTx)
+	if err != nil {
+		m.cond = math.Inf(1) // Numerically singular
+		return nil
+	}
+	dInv := matrix.NewVector(p)
+	for j := range d {
+		dInv[j] = 1 / d[j]
+	}
+	r, err := matrix.Multiply(matrix.Transpose(chol.L()), matrix.NewDiagonal(dInv))
+	if err != nil {
+		return err
+	}
+	return m.setCond(r)
+}
+
+// How far e_j can be from the row space of X for coefficient j to be estimable
+const estimableTolerance = 1e-8
+
+// Store the coefficients and (X'X)^-1 estimated from the columns `cols` of X,
+// the coefficients of any other columns are aliased
+func (m *Model) setEstimates(cols []int, coef, xTx_inv matrix.Matrix) {
+	p := len(m.names)
+	m.coef = matrix.NewVector(p)
+	m.xTx_inv = matrix.Zero(p, p)
+	for j := 0; j < p; j++ {
+		m.coef[j] = math.NaN()
+		for k := 0; k < p; k++ {
+			m.xTx_inv.Set(j, k, math.NaN())
+		}
+	}
+
+	for a, j := range cols {
+		m.coef[j] = coef.At(a, 0)
+		for b, k := range cols {
+			m.xTx_inv.Set(j, k, xTx_inv.At(a, b))
+		}
+	}
+	m.rank = len(cols)
+}
+
+// Store the condition number of the design from R, the triangular factor of
+// the QR decomposition of the columns which weren't aliased. Every solver uses
+// this definition, so they agree on which designs are ill-conditioned.
+func (m *Model) setCond(r matrix.Matrix) error {
+	cond, err := matrix.CondEstimate(r)
+	m.cond = cond
+	return err
+}
+
+// Returns 0, 1, ..., p-1
+func allColumns(p int) []int {
+	z := make([]int, p)
+	for j := range z {
+		z[j] = j
+	}
+	return z
+}
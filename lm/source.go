@@ -0,0 +1,48 @@
+package lm
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+)
+
+// A source of tabular data. The first record is the column names and every
+// record after it is a row of values.
+type Source interface {
+	Records() ([][]string, error)
+}
+
+// Records already in memory
+type Records [][]string
+
+func (r Records) Records() ([][]string, error) {
+	return r, nil
+}
+
+// A CSV file on disk
+type CSVFile string
+
+func (path CSVFile) Records() ([][]string, error) {
+	return ReadFromCSV(string(path))
+}
+
+// CSV read from any reader, such as a request body
+type CSVReader struct {
+	R io.Reader
+}
+
+func (c CSVReader) Records() ([][]string, error) {
+	return csv.NewReader(c.R).ReadAll()
+}
+
+// Read all of the records from the CSV file at `filepath`
+func ReadFromCSV(filepath string) ([][]string, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return [][]string{}, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	return r.ReadAll()
+}
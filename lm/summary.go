@@ -0,0 +1,231 @@
+package lm
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"ols/distributions"
+	"ols/matrix"
+	"slices"
+	"strings"
+)
+
+// A row of the coefficient table
+type Coefficient struct {
+	Name     string
+	Estimate float64
+	StdErr   float64
+	T        float64 // t statistic for the hypothesis that the coefficient is zero
+	P        float64 // Two-sided p-value of `T`
+	Aliased  bool    // Dropped from the fit as a linear combination of other coefficients, the rest are NaN
+	// With SolverSVD and collinear columns, only the minimum norm makes the
+	// estimate unique, so `T` and `P` are NaN
+	Inestimable bool
+}
+
+// The summary of a fitted model, like R's summary.lm
+type Summary struct {
+	Formula      string
+	Residuals    []float64
+	Coefficients []Coefficient
+
+	Rank        int     // Rank of the design matrix, less than the number of coefficients if they are collinear
+	Cond        float64 // Estimated condition number of the design matrix
+	IllCond     bool    // Whether `Cond` is over the limit the model was fitted with
+	Sigma       float64 // Residual standard error
+	DF          int     // Residual degrees of freedom
+	Omitted     int     // Rows left out because they were missing a value
+	PerfectFit  bool    // Whether the residuals are negligible, so the standard errors and tests are meaningless
+	RSquared    float64
+	AdjRSquared float64
+
+	// The F test of all coefficients but the intercept being zero
+	FStatistic float64
+	FDF1, FDF2 int
+	FP         float64
+}
+
+// Calculate the summary statistics of the model
+func (m *Model) Summary() (Summary, error) {
+	n := len(m.y)
+	p := m.rank
+	df := n - p
+	if df <= 0 {
+		return Summary{}, fmt.Errorf("no residual degrees of freedom, %d observations for %d coefficients", n, p)
+	}
+
+	s := Summary{
+		Formula:   m.formula.String(),
+		Residuals: m.Residuals(),
+		Rank:      p,
+		Cond:      m.cond,
+		IllCond:   m.IllConditioned(),
+		DF:        df,
+		Omitted:   m.omitted,
+	}
+
+	rss, _ := matrix.Vector(s.Residuals).Dot(s.Residuals)
+	mean := m.y.Mean()
+
+	// Without an intercept R compares against a model of zero rather than the mean
+	var tss float64
+	for _, d := range m.y {
+		if m.formula.Intercept {
+			d -= mean
+		}
+		tss += d * d
+	}
+
+	s.Sigma = math.Sqrt(rss / float64(df))
+
+	// As R, the residual variance is only rounding error next to the fitted values
+	fitted, _ := m.fitted.Dot(m.fitted)
+	s.PerfectFit = rss/float64(df) < 1e-30*fitted/float64(n)
+	for j, name := range m.names {
+		c := Coefficient{
+			Name:     name,
+			Estimate: m.coef[j],
+			StdErr:   s.Sigma * math.Sqrt(m.xTx_inv.At(j, j)),
+		}
+		if math.IsNaN(c.Estimate) {
+			c.T, c.P, c.Aliased = math.NaN(), math.NaN(), true
+			s.Coefficients = append(s.Coefficients, c)
+			continue
+		}
+		if m.inestimable != nil && m.inestimable[j] {
+			c.T, c.P, c.Inestimable = math.NaN(), math.NaN(), true
+			s.Coefficients = append(s.Coefficients, c)
+			continue
+		}
+		c.T = c.Estimate / c.StdErr
+		c.P = 2 * distributions.StudentsT{Nu: float64(df)}.Survival(math.Abs(c.T))
+		s.Coefficients = append(s.Coefficients, c)
+	}
+
+	intercept := 0
+	if m.formula.Intercept {
+		intercept = 1
+	}
+	s.RSquared = 1 - rss/tss
+	s.AdjRSquared = 1 - (1-s.RSquared)*float64(n-intercept)/float64(df)
+
+	s.FDF1 = p - intercept
+	s.FDF2 = df
+	if s.FDF1 > 0 {
+		s.FStatistic = ((tss - rss) / float64(s.FDF1)) / (rss / float64(df))
+		s.FP = distributions.F{D1: float64(s.FDF1), D2: float64(s.FDF2)}.Survival(s.FStatistic)
+	}
+
+	return s, nil
+}
+
+// Format a p-value, tiny values are indistinguishable from zero in double precision
+func formatP(p float64) string {
+	if p < 2.2e-16 {
+		return "<2e-16"
+	}
+	return fmt.Sprintf("%.3g", p)
+}
+
+func stars(p float64) string {
+	switch {
+	case p < 0.001:
+		return "***"
+	case p < 0.01:
+		return "**"
+	case p < 0.05:
+		return "*"
+	case p < 0.1:
+		return "."
+	}
+	return ""
+}
+
+// Quantile of sorted values `x`, using linear interpolation as R does by
+// default. NaN if there are no values.
+func quantile(x []float64, q float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	h := q * float64(len(x)-1)
+	lo := math.Floor(h)
+	if int(lo)+1 >= len(x) {
+		return x[len(x)-1]
+	}
+	return x[int(lo)] + (h-lo)*(x[int(lo)+1]-x[int(lo)])
+}
+
+// Write the summary in the style of R
+func (s Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "Call:\n%s\n\n", s.Formula)
+
+	// A zero Summary, as returned with an error, has no residuals
+	if len(s.Residuals) > 0 {
+		sorted := slices.Clone(s.Residuals)
+		slices.Sort(sorted)
+		fmt.Fprintf(w, "Residuals:\n%10s %10s %10s %10s %10s\n", "Min", "1Q", "Median", "3Q", "Max")
+		fmt.Fprintf(w, "%10.4g %10.4g %10.4g %10.4g %10.4g\n\n",
+			quantile(sorted, 0), quantile(sorted, 0.25), quantile(sorted, 0.5), quantile(sorted, 0.75), quantile(sorted, 1))
+	}
+
+	width := 0
+	for _, c := range s.Coefficients {
+		width = max(width, len(c.Name))
+	}
+	var aliased, inestimable []string
+	for _, c := range s.Coefficients {
+		if c.Aliased {
+			aliased = append(aliased, c.Name)
+		}
+		if c.Inestimable {
+			inestimable = append(inestimable, c.Name)
+		}
+	}
+
+	fmt.Fprint(w, "Coefficients:")
+	if len(aliased) > 0 {
+		fmt.Fprintf(w, " (%d not defined because of singularities)", len(aliased))
+	}
+	fmt.Fprintf(w, "\n%-*s %12s %12s %8s %9s\n", width, "", "Estimate", "Std. Error", "t value", "Pr(>|t|)")
+	for _, c := range s.Coefficients {
+		line := fmt.Sprintf("%-*s %12.5g %12.5g %8.3f %9s %s", width, c.Name, c.Estimate, c.StdErr, c.T, formatP(c.P), stars(c.P))
+		switch {
+		case c.Aliased:
+			line = fmt.Sprintf("%-*s %12s %12s %8s %9s", width, c.Name, "NA", "NA", "NA", "NA")
+		case c.Inestimable:
+			line = fmt.Sprintf("%-*s %12.5g %12.5g %8s %9s", width, c.Name, c.Estimate, c.StdErr, "NA", "NA")
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	switch {
+	case len(aliased) > 0:
+		fmt.Fprintf(w, "Note: aliased with earlier terms: %s\n", strings.Join(aliased, ", "))
+	case s.Rank < len(s.Coefficients):
+		fmt.Fprintf(w, "Note: the design matrix has rank %d for %d coefficients, these are the minimum norm estimates\n", s.Rank, len(s.Coefficients))
+		if len(inestimable) > 0 {
+			fmt.Fprintf(w, "Note: not estimable, only combinations with collinear terms are: %s\n", strings.Join(inestimable, ", "))
+		}
+	}
+	if s.PerfectFit {
+		fmt.Fprintf(w, "Note: essentially perfect fit, the summary may be unreliable\n")
+	}
+	if s.IllCond {
+		fmt.Fprintf(w, "Note: the condition number is large, %.3g, the columns may be close to collinear or badly scaled\n", s.Cond)
+	}
+	fmt.Fprintf(w, "---\nSignif. codes:  0 '***' 0.001 '**' 0.01 '*' 0.05 '.' 0.1 ' ' 1\n\n")
+
+	fmt.Fprintf(w, "Residual standard error: %.4g on %d degrees of freedom\n", s.Sigma, s.DF)
+	if s.Omitted > 0 {
+		fmt.Fprintf(w, "  (%d observations deleted due to missingness)\n", s.Omitted)
+	}
+	fmt.Fprintf(w, "Multiple R-squared:  %.4g,\tAdjusted R-squared:  %.4g\n", s.RSquared, s.AdjRSquared)
+	if s.FDF1 > 0 {
+		fmt.Fprintf(w, "F-statistic: %.4g on %d and %d DF,  p-value: %s\n", s.FStatistic, s.FDF1, s.FDF2, formatP(s.FP))
+	}
+}
+
+func (s Summary) String() string {
+	var b strings.Builder
+	s.Print(&b)
+	return b.String()
+}
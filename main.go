@@ -1,60 +1,91 @@
 package main
 
 import (
-	"encoding/csv"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"ols/formula"
+	"ols/lm"
 	"ols/matrix"
 	"os"
-	"slices"
-	"strconv"
+	"strings"
 )
 
+// Reference levels for factors given as `-ref name=level`
+type refFlag map[string]string
+
+func (r refFlag) String() string {
+	return fmt.Sprint(map[string]string(r))
+}
+
+func (r refFlag) Set(s string) error {
+	name, level, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=level, got '%s'", s)
+	}
+	r[name] = level
+	return nil
+}
+
+var refs = refFlag{}
+
+var solver lm.Solver
+
+var iterative matrix.IterativeOptions
+
+var maxCond float64
+
+var strict bool
+
 func init() {
+	flag.Var(refs, "ref", "reference `name=level` for a factor, may be repeated")
+	flag.Func("solver", "least squares `method`, qr (default), cholesky, svd or lsqr", func(s string) error {
+		var err error
+		solver, err = lm.ParseSolver(s)
+		return err
+	})
+	flag.Float64Var(&iterative.Tolerance, "tol", matrix.DefaultTolerance, "relative `tolerance` at which -solver lsqr stops")
+	flag.IntVar(&iterative.MaxIterations, "maxiter", 0, "most `iterations` of -solver lsqr, 0 for 10 per coefficient")
+	flag.Float64Var(&maxCond, "maxcond", lm.DefaultMaxCondition, "`limit` on the condition number of the design matrix, above which the fit is noted as ill-conditioned")
+	flag.BoolVar(&strict, "strict", false, "fail rather than note an ill-conditioned fit")
 	flag.Usage = func() {
-		fmt.Print("usage: ols <input.csv> <response> ~ [exploratory]\n")
+		fmt.Print("usage: ols [-ref name=level ...] [-solver method [-tol t] [-maxiter n]] [-maxcond c] [-strict] <input.csv> ['<response> ~ <terms>']\n")
+		flag.PrintDefaults()
 	}
 	flag.Parse()
 }
 
-func ParseEq(eq []string) (string, []string, error) {
+// Join the formula arguments back into a single string. An unquoted `~` will
+// have been expanded by the shell into the home directory, so put it back.
+func FormulaArg(args []string) string {
 	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", []string{}, err
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if err == nil && arg == home {
+			arg = "~"
+		}
+		parts[i] = arg
 	}
+	return strings.Join(parts, " ")
+}
 
-	if eq[1] != home {
-		return "", []string{}, fmt.Errorf("Expected tilde")
+// Print a formula error with a marker under the offending position
+func formulaError(src string, err error) {
+	fmt.Fprintf(os.Stderr, "error: %s\n", err)
+	var ferr *formula.Error
+	if errors.As(err, &ferr) {
+		fmt.Fprintf(os.Stderr, "  %s\n  %s^\n", src, strings.Repeat(" ", ferr.Pos))
 	}
-
-	args := slices.DeleteFunc(eq[2:], func(s string) bool {
-		return s == "+"
-	})
-
-	return eq[0], args, nil
 }
 
 func main() {
 	args := flag.Args()
 	if len(args) < 1 {
 		flag.Usage()
+		os.Exit(2)
 	}
-	records, err := ReadFromCSV(args[0])
-
-	var y string
-	var xs []string
-	if len(args) > 1 {
-		var err error
-		y, xs, err = ParseEq(args[1:])
-		if err != nil {
-			flag.Usage()
-			log.Fatal(err)
-		}
-	} else {
-		y = records[0][0]
-		xs = records[0][1:]
-	}
+	records, err := lm.ReadFromCSV(args[0])
 	// Check if the error is that the file isn't real
 	if os.IsNotExist(err) {
 		flag.Usage()
@@ -63,113 +94,42 @@ func main() {
 		flag.Usage()
 		log.Fatal(err)
 	}
-
-	mod, err := OLS(records, y, xs)
-	if err != nil {
-		log.Fatal(err)
+	if len(records) < 1 {
+		log.Fatalf("error: file '%s' is empty\n", args[0])
 	}
 
-	fmt.Print(mod.coef)
-}
-
-type model struct {
-	dep          string
-	dep_n        int
-	ind          []string
-	ind_n        map[int]int
-	fitted, coef matrix.Matrix
-}
-
-func OLS(records [][]string, D string, Es []string) (model, error) {
-	// For assume:
-	//  - the first row is names
-	names := records[0]
-	Xs_ind := make(map[int]int, len(names))
-	var y_ind int
-	counter := 1
-	for i, name := range names {
-		for _, E := range Es {
-			if name == E {
-				Xs_ind[i] = counter
-				counter += 1
-			}
-		}
-		if name == D {
-			y_ind = i
+	var f *formula.Formula
+	if len(args) > 1 {
+		src := FormulaArg(args[1:])
+		f, err = formula.Parse(src)
+		if err != nil {
+			formulaError(src, err)
+			os.Exit(1)
 		}
-	}
-
-	n := len(records) - 1
-	y := matrix.Zero(n, 1)
-	X := matrix.Zero(n, counter)
-	for i, row := range records[1:] {
-		X.Set(i, 0, 1) // intercept
-		for j, v := range row {
-			if v == "" {
-				break
-			}
-			val, err := strconv.ParseFloat(v, 64)
-			if err != nil {
-				fmt.Printf("error: parsing error, '%s' as a result of entry '%s'", err, v)
-				os.Exit(1)
-			}
-			if j == y_ind {
-				y.Set(i, 0, val)
-			} else if key, ok := Xs_ind[j]; ok {
-				X.Set(i, key, val)
-			} else {
-				continue
-			}
+	} else {
+		// Regress the first column on all the others
+		f = &formula.Formula{Response: records[0][0], Intercept: true}
+		for _, name := range records[0][1:] {
+			f.Terms = append(f.Terms, formula.Term{name})
 		}
 	}
 
-	xT := matrix.Transpose(X)
-	xTx, err := matrix.Multiply(xT, X)
-	if err != nil {
-		return model{}, err
-	}
-	xTx_inv, err := matrix.Inverse(xTx)
-	if err != nil {
-		return model{}, err
-	}
-	xTx_inv_xT, err := matrix.Multiply(xTx_inv, xT)
-	if err != nil {
-		return model{}, err
-	}
-
-	hat, err := matrix.Multiply(X, xTx_inv_xT)
-	if err != nil {
-		return model{}, err
+	opts := []lm.Option{lm.WithSolver(solver), lm.WithIterativeOptions(iterative), lm.WithMaxCondition(maxCond)}
+	if strict {
+		opts = append(opts, lm.WithStrict())
 	}
-
-	fitted, err := matrix.Multiply(hat, y)
-	if err != nil {
-		return model{}, err
+	for name, level := range refs {
+		opts = append(opts, lm.WithReference(name, level))
 	}
 
-	coef, err := matrix.Multiply(xTx_inv_xT, y)
+	mod, err := lm.FitFormula(lm.Records(records), f, opts...)
 	if err != nil {
-		return model{}, err
-	}
-
-	mod := model{
-		dep:    D,
-		dep_n:  y_ind,
-		ind:    Es,
-		ind_n:  Xs_ind,
-		fitted: fitted,
-		coef:   coef,
+		log.Fatal(err)
 	}
 
-	return mod, nil
-}
-
-func ReadFromCSV(filepath string) ([][]string, error) {
-	file, err := os.Open(filepath)
+	s, err := mod.Summary()
 	if err != nil {
-		return [][]string{}, err
+		log.Fatal(err)
 	}
-
-	r := csv.NewReader(file)
-	return r.ReadAll()
+	s.Print(os.Stdout)
 }
@@ -0,0 +1,79 @@
+package matrix
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// A fully populated design matrix like those in OLS
+func benchDesign(n, m int) *Dense {
+	r := rand.New(rand.NewSource(1))
+	x := NewDense(n, m)
+	for i := range x.Values {
+		x.Values[i] = r.NormFloat64()
+	}
+	return x
+}
+
+// Forming X'X, the most expensive step of the normal equations
+func BenchmarkMultiply(b *testing.B) {
+	x := benchDesign(5000, 10)
+	representations := []struct {
+		name string
+		x    Matrix
+	}{
+		{"dense", x},
+		{"sparse", toSparse(x)},
+		{"csr", ToCSR(x)},
+	}
+
+	for _, r := range representations {
+		xT := Transpose(r.x)
+		b.Run(r.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Multiply(xT, r.x)
+			}
+		})
+	}
+}
+
+// The least squares solve and fitted values of OLS
+func BenchmarkLeastSquares(b *testing.B) {
+	x := benchDesign(5000, 10)
+	y := benchDesign(5000, 1)
+	representations := []struct {
+		name string
+		x    Matrix
+	}{
+		{"dense", x},
+		{"sparse", toSparse(x)},
+		{"csr", ToCSR(x)},
+	}
+
+	for _, r := range representations {
+		b.Run(r.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				qr, _ := DecomposeQR(r.x)
+				coef, _ := qr.Solve(y)
+				Multiply(r.x, coef)
+			}
+		})
+	}
+}
+
+// X'X of a tall design, on one goroutine and shared between four
+func BenchmarkMultiplyTall(b *testing.B) {
+	defer SetWorkers(Workers())
+	x := benchDesign(200000, 20)
+	xT := Transpose(x)
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			SetWorkers(workers)
+			for i := 0; i < b.N; i++ {
+				Multiply(xT, x)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+/*
+The Cholesky decomposition of a symmetric positive definite `N` x `N` matrix,
+`A = LL'` where L is lower triangular with a positive diagonal. This takes half
+the work of LU and needs no pivoting.
+*/
+type Cholesky struct {
+	l []float64 // Row major, zero above the diagonal
+	N int
+}
+
+// Decompose `x` into LL'. Only the lower triangle of `x` is read, so it is
+// assumed to be symmetric, and this fails if it isn't (numerically) positive
+// definite.
+func DecomposeCholesky(x Matrix) (Cholesky, error) {
+	if b, err := isSquare(x); !b {
+		return Cholesky{}, err
+	}
+	n, _ := x.Dims()
+
+	l := make([]float64, n*n)
+	for j := 0; j < n; j++ {
+		d := x.At(j, j)
+		for k := 0; k < j; k++ {
+			d -= l[j*n+k] * l[j*n+k]
+		}
+		// What is left of the diagonal is the squared norm of the part of column j
+		// independent of the earlier columns (for A = X'X), so compare it to the
+		// column as a whole like QR's rank check. Also catches NaN.
+		if !(d > 0) || d <= RankTolerance*RankTolerance*x.At(j, j) {
+			return Cholesky{}, fmt.Errorf("Matrix is not positive definite")
+		}
+		l[j*n+j] = math.Sqrt(d)
+
+		for i := j + 1; i < n; i++ {
+			s := x.At(i, j)
+			for k := 0; k < j; k++ {
+				s -= l[i*n+k] * l[j*n+k]
+			}
+			l[i*n+j] = s / l[j*n+j]
+		}
+	}
+
+	return Cholesky{l: l, N: n}, nil
+}
+
+// Returns the lower triangular factor L
+func (f Cholesky) L() Matrix {
+	return &Triangular{Values: slices.Clone(f.l), N: f.N}
+}
+
+// Returns the determinant, the squared product of the diagonal of L
+func (f Cholesky) Det() float64 {
+	det := 1.0
+	for k := 0; k < f.N; k++ {
+		det *= f.l[k*f.N+k]
+	}
+	return det * det
+}
+
+// Returns the log of the determinant, which is always positive. This won't
+// overflow where Det would for large matrices.
+func (f Cholesky) LogDet() float64 {
+	logDet := 0.0
+	for k := 0; k < f.N; k++ {
+		logDet += math.Log(f.l[k*f.N+k])
+	}
+	return 2 * logDet
+}
+
+// Returns X solving AX = b, where A = LL', by solving L Y = b then L'X = Y
+func (f Cholesky) Solve(b Matrix) (Matrix, error) {
+	bn, cols := b.Dims()
+	if bn != f.N {
+		return nil, fmt.Errorf("Expected `b` to have %d rows, got %d", f.N, bn)
+	}
+
+	n := f.N
+	z := toDense(b)
+
+	// Forward substitution with L
+	for k := 0; k < n; k++ {
+		for j := 0; j < cols; j++ {
+			z[k*cols+j] /= f.l[k*n+k]
+		}
+		for i := k + 1; i < n; i++ {
+			l := f.l[i*n+k]
+			for j := 0; j < cols; j++ {
+				z[i*cols+j] -= l * z[k*cols+j]
+			}
+		}
+	}
+
+	// Back substitution with L'
+	for k := n - 1; k >= 0; k-- {
+		for j := 0; j < cols; j++ {
+			z[k*cols+j] /= f.l[k*n+k]
+		}
+		for i := 0; i < k; i++ {
+			l := f.l[k*n+i]
+			for j := 0; j < cols; j++ {
+				z[i*cols+j] -= l * z[k*cols+j]
+			}
+		}
+	}
+
+	return fromDense(n, cols, z), nil
+}
+
+// Returns the inverse of A = LL', which is symmetric
+func (f Cholesky) Inverse() Matrix {
+	id := NewDiagonal(make([]float64, f.N))
+	for i := range id.Values {
+		id.Values[i] = 1
+	}
+	z, _ := f.Solve(id) // Can't fail, the dimensions match
+	return z
+}
@@ -0,0 +1,87 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecomposeCholesky(t *testing.T) {
+	// X'X of a full rank design, symmetric positive definite
+	x := fromSliceOfSlices([][]float64{
+		{1, 1, 0},
+		{1, 2, 1},
+		{1, 3, 0},
+		{1, 4, 1},
+		{1, 5, 1},
+	})
+	a, _ := Multiply(Transpose(x), x)
+
+	t.Run("LL' = A", func(t *testing.T) {
+		chol, err := DecomposeCholesky(a)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		l := chol.L()
+		if b, _ := IsLowerTriangular(l); !b {
+			t.Errorf("expected L to be lower triangular, got %v", l)
+		}
+		got, _ := Multiply(l, Transpose(l))
+		if !near(got, a, 1e-12) {
+			t.Errorf("expected LL' = A, got %v, want %v", got, a)
+		}
+	})
+
+	t.Run("determinants agree with LU", func(t *testing.T) {
+		chol, _ := DecomposeCholesky(a)
+		want, _ := Det(a)
+		if got := chol.Det(); math.Abs(got-want) > 1e-9 {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if got := chol.LogDet(); math.Abs(got-math.Log(want)) > 1e-12 {
+			t.Errorf("expected to be the same, got %v, want %v", got, math.Log(want))
+		}
+	})
+
+	t.Run("solve and inverse", func(t *testing.T) {
+		chol, _ := DecomposeCholesky(a)
+		b := fromSliceOfSlices([][]float64{{1, 0}, {2, 1}, {0, 3}})
+		got, err := chol.Solve(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ax, _ := Multiply(a, got)
+		if !near(ax, b, 1e-12) {
+			t.Errorf("expected AX = b, got %v, want %v", ax, b)
+		}
+
+		id, _ := Multiply(a, chol.Inverse())
+		if !near(id, Identity(3), 1e-12) {
+			t.Errorf("expected A A^-1 = I, got %v", id)
+		}
+
+		if _, err := chol.Solve(Zero(2, 1)); err == nil {
+			t.Errorf("expected solve to fail")
+		}
+	})
+
+	t.Run("fail on matrices which aren't positive definite", func(t *testing.T) {
+		for _, m := range []Matrix{
+			fromSliceOfSlices([][]float64{{1, 2}, {2, 1}}), // Indefinite
+			fromSliceOfSlices([][]float64{{1, 2}, {2, 4}}), // Singular
+			fromSliceOfSlices([][]float64{{-1, 0}, {0, -1}}),
+		} {
+			if _, err := DecomposeCholesky(m); err == nil {
+				t.Errorf("expected Cholesky of %v to fail", m)
+			}
+			if b, _ := IsPositiveDefinite(m); b {
+				t.Errorf("expected %v not to be positive definite", m)
+			}
+		}
+		if b, _ := IsPositiveDefinite(a); !b {
+			t.Errorf("expected %v to be positive definite", a)
+		}
+		if _, err := IsPositiveDefinite(Zero(2, 3)); err == nil {
+			t.Errorf("expected IsPositiveDefinite to fail")
+		}
+	})
+}
@@ -0,0 +1,460 @@
+package matrix
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+/*
+A CSR (compressed sparse row) matrix stores the non-zeros of each row
+contiguously. The non-zeros of row `i` have columns `ColIdx[RowPtr[i]:RowPtr[i+1]]`,
+in increasing order, and values `Values[RowPtr[i]:RowPtr[i+1]]`. This takes an
+int and a float per non-zero, rather than a map entry, and iterates rows in
+order.
+*/
+type CSR struct {
+	RowPtr []int
+	ColIdx []int
+	Values []float64
+	// Number of Rows and Columns
+	N, M int
+}
+
+/*
+A CSC (compressed sparse column) matrix is the column counterpart of CSR. The
+non-zeros of column `j` have rows `RowIdx[ColPtr[j]:ColPtr[j+1]]`, in increasing
+order, and values `Values[ColPtr[j]:ColPtr[j+1]]`.
+*/
+type CSC struct {
+	ColPtr []int
+	RowIdx []int
+	Values []float64
+	// Number of Rows and Columns
+	N, M int
+}
+
+// A single element of a matrix, used to build compressed matrices
+type Triplet struct {
+	I, J int
+	V    float64
+}
+
+// Create a `n` x `m` CSR matrix from `triplets`, duplicates are summed
+func NewCSR(n, m int, triplets []Triplet) (*CSR, error) {
+	ptr, idx, values, err := compress(n, m, triplets, false)
+	if err != nil {
+		return nil, err
+	}
+	return &CSR{RowPtr: ptr, ColIdx: idx, Values: values, N: n, M: m}, nil
+}
+
+// Create a `n` x `m` CSC matrix from `triplets`, duplicates are summed
+func NewCSC(n, m int, triplets []Triplet) (*CSC, error) {
+	ptr, idx, values, err := compress(n, m, triplets, true)
+	if err != nil {
+		return nil, err
+	}
+	return &CSC{ColPtr: ptr, RowIdx: idx, Values: values, N: n, M: m}, nil
+}
+
+// Returns the non-zeros of `x` as triplets
+func nonZeroTriplets(x Matrix) []Triplet {
+	var z []Triplet
+	x.NonZeros(func(i, j int, v float64) {
+		z = append(z, Triplet{I: i, J: j, V: v})
+	})
+	return z
+}
+
+// Returns `x` in CSR format
+func ToCSR(x Matrix) *CSR {
+	switch x := x.(type) {
+	case *CSR:
+		return x.Copy()
+	case *CSC:
+		ptr, idx, values := transposeCompressed(x.M, x.N, x.ColPtr, x.RowIdx, x.Values)
+		return &CSR{RowPtr: ptr, ColIdx: idx, Values: values, N: x.N, M: x.M}
+	}
+
+	n, m := x.Dims()
+	z := &CSR{RowPtr: make([]int, n+1), N: n, M: m}
+	x.NonZeros(func(i, j int, v float64) {
+		z.ColIdx = append(z.ColIdx, j)
+		z.Values = append(z.Values, v)
+		z.RowPtr[i+1]++
+	})
+	for i := 0; i < n; i++ {
+		z.RowPtr[i+1] += z.RowPtr[i]
+	}
+	return z
+}
+
+// Returns `x` in CSC format
+func ToCSC(x Matrix) *CSC {
+	if x, ok := x.(*CSC); ok {
+		return x.Copy()
+	}
+	r := ToCSR(x)
+	ptr, idx, values := transposeCompressed(r.N, r.M, r.RowPtr, r.ColIdx, r.Values)
+	return &CSC{ColPtr: ptr, RowIdx: idx, Values: values, N: r.N, M: r.M}
+}
+
+// Returns `x` as a map based Sparse matrix
+func ToSparse(x Matrix) *Sparse {
+	if x, ok := x.(*Sparse); ok {
+		return x.Copy()
+	}
+	n, m := x.Dims()
+	z := NewSparse(n, m)
+	x.NonZeros(func(i, j int, v float64) {
+		z.Values[[2]int{i, j}] = v
+	})
+	return z
+}
+
+func (x *CSR) Dims() (int, int) {
+	return x.N, x.M
+}
+
+// Get the value in a matrix at a point
+func (x *CSR) At(i, j int) float64 {
+	if p, ok := find(x.RowPtr, x.ColIdx, i, j); ok {
+		return x.Values[p]
+	}
+	return 0
+}
+
+// Set a matrix value at a point. Adding a new non-zero has to shift the
+// following elements, so build from triplets where possible.
+func (x *CSR) Set(i, j int, v float64) error {
+	if i >= x.N || j >= x.M || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	x.ColIdx, x.Values = set(x.RowPtr, x.ColIdx, x.Values, i, j, v)
+	return nil
+}
+
+// Call `fn` for each non-zero element, in row major order
+func (x *CSR) NonZeros(fn func(i, j int, v float64)) {
+	for i := 0; i < x.N; i++ {
+		for p := x.RowPtr[i]; p < x.RowPtr[i+1]; p++ {
+			fn(i, x.ColIdx[p], x.Values[p])
+		}
+	}
+}
+
+// Copy a matrix in it's entirety
+func (x *CSR) Copy() *CSR {
+	return &CSR{
+		RowPtr: slices.Clone(x.RowPtr),
+		ColIdx: slices.Clone(x.ColIdx),
+		Values: slices.Clone(x.Values),
+		N:      x.N,
+		M:      x.M,
+	}
+}
+
+// The proportion of elements which are non-zero
+func (x *CSR) Density() float64 {
+	if x.N == 0 || x.M == 0 {
+		return 0
+	}
+	return float64(len(x.Values)) / (float64(x.N) * float64(x.M))
+}
+
+func (x *CSC) Dims() (int, int) {
+	return x.N, x.M
+}
+
+// Get the value in a matrix at a point
+func (x *CSC) At(i, j int) float64 {
+	if p, ok := find(x.ColPtr, x.RowIdx, j, i); ok {
+		return x.Values[p]
+	}
+	return 0
+}
+
+// Set a matrix value at a point. Adding a new non-zero has to shift the
+// following elements, so build from triplets where possible.
+func (x *CSC) Set(i, j int, v float64) error {
+	if i >= x.N || j >= x.M || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	x.RowIdx, x.Values = set(x.ColPtr, x.RowIdx, x.Values, j, i, v)
+	return nil
+}
+
+// Call `fn` for each non-zero element, in row major order
+func (x *CSC) NonZeros(fn func(i, j int, v float64)) {
+	ptr, idx, values := transposeCompressed(x.M, x.N, x.ColPtr, x.RowIdx, x.Values)
+	for i := 0; i < x.N; i++ {
+		for p := ptr[i]; p < ptr[i+1]; p++ {
+			fn(i, idx[p], values[p])
+		}
+	}
+}
+
+// Copy a matrix in it's entirety
+func (x *CSC) Copy() *CSC {
+	return &CSC{
+		ColPtr: slices.Clone(x.ColPtr),
+		RowIdx: slices.Clone(x.RowIdx),
+		Values: slices.Clone(x.Values),
+		N:      x.N,
+		M:      x.M,
+	}
+}
+
+// The proportion of elements which are non-zero
+func (x *CSC) Density() float64 {
+	if x.N == 0 || x.M == 0 {
+		return 0
+	}
+	return float64(len(x.Values)) / (float64(x.N) * float64(x.M))
+}
+
+/*
+The helpers below work on the compressed arrays themselves, where `ptr` indexes
+the `major` dimension (rows for CSR, columns for CSC) and `idx` holds the
+`minor` index of each value. A CSC matrix has the same arrays as the CSR of its
+transpose, so each algorithm serves both formats.
+*/
+
+// Sort `triplets` into compressed arrays, summing duplicates and dropping zeros
+func compress(n, m int, triplets []Triplet, byCol bool) ([]int, []int, []float64, error) {
+	for _, t := range triplets {
+		if t.I >= n || t.J >= m || t.I < 0 || t.J < 0 {
+			return nil, nil, nil, fmt.Errorf("Triplet (%d, %d) is outside of a %d x %d matrix", t.I, t.J, n, m)
+		}
+	}
+
+	key := func(t Triplet) (int, int) { return t.I, t.J }
+	major := n
+	if byCol {
+		key = func(t Triplet) (int, int) { return t.J, t.I }
+		major = m
+	}
+
+	sorted := slices.Clone(triplets)
+	slices.SortStableFunc(sorted, func(a, b Triplet) int {
+		ai, aj := key(a)
+		bi, bj := key(b)
+		if ai != bi {
+			return cmp.Compare(ai, bi)
+		}
+		return cmp.Compare(aj, bj)
+	})
+
+	ptr := make([]int, major+1)
+	var idx []int
+	var values []float64
+	for k, t := range sorted {
+		i, j := key(t)
+		if k > 0 {
+			pi, pj := key(sorted[k-1])
+			if pi == i && pj == j {
+				values[len(values)-1] += t.V
+				continue
+			}
+		}
+		idx = append(idx, j)
+		values = append(values, t.V)
+		ptr[i+1]++
+	}
+	for i := 0; i < major; i++ {
+		ptr[i+1] += ptr[i]
+	}
+
+	idx, values = prune(ptr, idx, values, 0)
+	return ptr, idx, values, nil
+}
+
+// Remove stored values smaller than `tol`, and any zeros, updating `ptr` in place
+func prune(ptr, idx []int, values []float64, tol float64) ([]int, []float64) {
+	k := 0
+	for i := 0; i < len(ptr)-1; i++ {
+		start, end := ptr[i], ptr[i+1]
+		ptr[i] = k
+		for p := start; p < end; p++ {
+			if v := values[p]; v != 0 && math.Abs(v) >= tol {
+				idx[k] = idx[p]
+				values[k] = v
+				k++
+			}
+		}
+	}
+	ptr[len(ptr)-1] = k
+	return idx[:k], values[:k]
+}
+
+// Position of element (`i`, `j`) in the compressed arrays, if it is stored
+func find(ptr, idx []int, i, j int) (int, bool) {
+	p, ok := slices.BinarySearch(idx[ptr[i]:ptr[i+1]], j)
+	return ptr[i] + p, ok
+}
+
+// Set element (`i`, `j`) in the compressed arrays, inserting or removing it as needed
+func set(ptr, idx []int, values []float64, i, j int, v float64) ([]int, []float64) {
+	p, ok := find(ptr, idx, i, j)
+	switch {
+	case ok && v != 0:
+		values[p] = v
+		return idx, values
+	case ok:
+		idx = slices.Delete(idx, p, p+1)
+		values = slices.Delete(values, p, p+1)
+		for k := i + 1; k < len(ptr); k++ {
+			ptr[k]--
+		}
+	case v != 0:
+		idx = slices.Insert(idx, p, j)
+		values = slices.Insert(values, p, v)
+		for k := i + 1; k < len(ptr); k++ {
+			ptr[k]++
+		}
+	}
+	return idx, values
+}
+
+// Swap the major and minor dimensions of compressed arrays, by counting sort
+func transposeCompressed(major, minor int, ptr, idx []int, values []float64) ([]int, []int, []float64) {
+	tptr := make([]int, minor+1)
+	for _, j := range idx {
+		tptr[j+1]++
+	}
+	for j := 0; j < minor; j++ {
+		tptr[j+1] += tptr[j]
+	}
+
+	tidx := make([]int, len(idx))
+	tvalues := make([]float64, len(values))
+	next := slices.Clone(tptr[:minor])
+	for i := 0; i < major; i++ {
+		for p := ptr[i]; p < ptr[i+1]; p++ {
+			q := next[idx[p]]
+			next[idx[p]]++
+			tidx[q] = i
+			tvalues[q] = values[p]
+		}
+	}
+	return tptr, tidx, tvalues
+}
+
+// Product of compressed `a`, with `n` major rows, and compressed `b`, with `m`
+// minor columns. Each row of the result accumulates scaled rows of `b`
+// (Gustavson's algorithm). Blocks of rows are shared between workers, then
+// joined in order.
+func multiplyCompressed(n, m int, aptr, aidx []int, avalues []float64, bptr, bidx []int, bvalues []float64) ([]int, []int, []float64) {
+	// Roughly the multiply-adds, each non-zero of `a` meets a row of `b`
+	work := 0
+	if n > 0 {
+		work = len(avalues) * (len(bvalues)/max(len(bptr)-1, 1) + 1)
+	}
+	workers := workerCount(work)
+	size := shareSize(n, workers)
+
+	type block struct {
+		counts []int
+		idx    []int
+		values []float64
+	}
+	blocks := make([]block, (n+size-1)/size)
+	parallelFor(n, size, workers, func(lo, hi int) {
+		b := block{counts: make([]int, hi-lo)}
+		acc := make([]float64, m)
+		seen := make([]bool, m)
+		var cols []int
+		for i := lo; i < hi; i++ {
+			cols = cols[:0]
+			for p := aptr[i]; p < aptr[i+1]; p++ {
+				k, a := aidx[p], avalues[p]
+				for q := bptr[k]; q < bptr[k+1]; q++ {
+					j := bidx[q]
+					if !seen[j] {
+						seen[j] = true
+						cols = append(cols, j)
+					}
+					acc[j] += a * bvalues[q]
+				}
+			}
+
+			slices.Sort(cols)
+			for _, j := range cols {
+				if acc[j] != 0 {
+					b.idx = append(b.idx, j)
+					b.values = append(b.values, acc[j])
+					b.counts[i-lo]++
+				}
+				acc[j] = 0
+				seen[j] = false
+			}
+		}
+		blocks[lo/size] = b
+	})
+
+	ptr := make([]int, n+1)
+	var idx []int
+	var values []float64
+	i := 0
+	for _, b := range blocks {
+		for _, c := range b.counts {
+			ptr[i+1] = ptr[i] + c
+			i++
+		}
+		idx = append(idx, b.idx...)
+		values = append(values, b.values...)
+	}
+	return ptr, idx, values
+}
+
+// Product of two CSR matrices, in CSR format
+func multiplyCSR(x, y *CSR) *CSR {
+	ptr, idx, values := multiplyCompressed(x.N, y.M, x.RowPtr, x.ColIdx, x.Values, y.RowPtr, y.ColIdx, y.Values)
+	return &CSR{RowPtr: ptr, ColIdx: idx, Values: values, N: x.N, M: y.M}
+}
+
+// Product of two CSC matrices, in CSC format. As (xy)' = y'x' this is the CSR
+// product of the transposes.
+func multiplyCSC(x, y *CSC) *CSC {
+	ptr, idx, values := multiplyCompressed(y.M, x.N, y.ColPtr, y.RowIdx, y.Values, x.ColPtr, x.RowIdx, x.Values)
+	return &CSC{ColPtr: ptr, RowIdx: idx, Values: values, N: x.N, M: y.M}
+}
+
+// Product of a CSR and a dense matrix, each non-zero scales a row of `y`.
+// Blocks of rows are shared between workers.
+func multiplyCSRDense(x *CSR, y *Dense) *Dense {
+	z := NewDense(x.N, y.M)
+	workers := workerCount(len(x.Values) * y.M)
+	parallelFor(x.N, shareSize(x.N, workers), workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			zi := z.Row(i)
+			for p := x.RowPtr[i]; p < x.RowPtr[i+1]; p++ {
+				a := x.Values[p]
+				for j, b := range y.Row(x.ColIdx[p]) {
+					zi[j] += a * b
+				}
+			}
+		}
+	})
+	return z
+}
+
+// Product of a dense and a CSC matrix, each non-zero scales a column of `x`.
+// Blocks of columns are shared between workers.
+func multiplyDenseCSC(x *Dense, y *CSC) *Dense {
+	z := NewDense(x.N, y.M)
+	workers := workerCount(len(y.Values) * x.N)
+	parallelFor(y.M, shareSize(y.M, workers), workers, func(lo, hi int) {
+		for j := lo; j < hi; j++ {
+			for p := y.ColPtr[j]; p < y.ColPtr[j+1]; p++ {
+				k, b := y.RowIdx[p], y.Values[p]
+				for i := 0; i < x.N; i++ {
+					z.Values[i*z.M+j] += x.Values[i*x.M+k] * b
+				}
+			}
+		}
+	})
+	return z
+}
@@ -0,0 +1,195 @@
+package matrix
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompressed(t *testing.T) {
+	dense := fromSliceOfSlices([][]float64{
+		{1, 0, 0, 2},
+		{0, 0, 0, 0},
+		{0, 3, 4, 0},
+	})
+	triplets := []Triplet{
+		{I: 2, J: 2, V: 4},
+		{I: 0, J: 3, V: 2},
+		{I: 2, J: 1, V: 1},
+		{I: 0, J: 0, V: 1},
+		{I: 2, J: 1, V: 2}, // Duplicates are summed
+		{I: 1, J: 1, V: 0}, // Zeros are dropped
+	}
+
+	t.Run("CSR from triplets", func(t *testing.T) {
+		x, err := NewCSR(3, 4, triplets)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !Equal(x, dense) {
+			t.Errorf("expected to be the same, got %v, want %v", x, dense)
+		}
+		want := &CSR{
+			RowPtr: []int{0, 2, 2, 4},
+			ColIdx: []int{0, 3, 1, 2},
+			Values: []float64{1, 2, 3, 4},
+			N:      3,
+			M:      4,
+		}
+		if !reflect.DeepEqual(x, want) {
+			t.Errorf("expected to be the same, got %v, want %v", x, want)
+		}
+	})
+
+	t.Run("CSC from triplets", func(t *testing.T) {
+		x, err := NewCSC(3, 4, triplets)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !Equal(x, dense) {
+			t.Errorf("expected to be the same, got %v, want %v", x, dense)
+		}
+		want := &CSC{
+			ColPtr: []int{0, 1, 2, 3, 4},
+			RowIdx: []int{0, 2, 2, 0},
+			Values: []float64{1, 3, 4, 2},
+			N:      3,
+			M:      4,
+		}
+		if !reflect.DeepEqual(x, want) {
+			t.Errorf("expected to be the same, got %v, want %v", x, want)
+		}
+	})
+
+	t.Run("fail on triplets outside the matrix", func(t *testing.T) {
+		if _, err := NewCSR(2, 2, []Triplet{{I: 2, J: 0, V: 1}}); err == nil {
+			t.Errorf("expected construction to fail")
+		}
+	})
+
+	t.Run("conversion round trips", func(t *testing.T) {
+		s := ToSparse(dense)
+		for _, x := range []Matrix{ToCSR(s), ToCSC(s), ToSparse(ToCSR(s)), ToSparse(ToCSC(s)), ToCSC(ToCSR(s))} {
+			if !Equal(x, dense) {
+				t.Errorf("expected to be the same, got %v, want %v", x, dense)
+			}
+		}
+		if got := len(ToSparse(ToCSC(dense)).Values); got != 4 {
+			t.Errorf("expected to be the same, got %v, want %v", got, 4)
+		}
+	})
+
+	t.Run("set inserts and removes non-zeros", func(t *testing.T) {
+		for _, x := range []Matrix{ToCSR(dense), ToCSC(dense)} {
+			want := Copy(dense)
+			for _, s := range []Triplet{{I: 1, J: 2, V: 5}, {I: 0, J: 0, V: 0}, {I: 2, J: 1, V: 6}, {I: 1, J: 0, V: 7}} {
+				if err := x.Set(s.I, s.J, s.V); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				want.Set(s.I, s.J, s.V)
+			}
+			if !Equal(x, want) {
+				t.Errorf("expected to be the same, got %v, want %v", x, want)
+			}
+			if !reflect.DeepEqual(ToCSR(x), ToCSR(want)) {
+				t.Errorf("expected the same storage, got %v, want %v", ToCSR(x), ToCSR(want))
+			}
+			if err := x.Set(3, 0, 1); err == nil {
+				t.Errorf("expected setting outside the matrix to fail")
+			}
+		}
+	})
+
+	t.Run("non-zeros are in row major order", func(t *testing.T) {
+		var got []float64
+		ToCSC(dense).NonZeros(func(i, j int, v float64) {
+			got = append(got, v)
+		})
+		if want := []float64{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestCompressedArithmetic(t *testing.T) {
+	x := fromSliceOfSlices([][]float64{
+		{1, 0, 0, 2},
+		{0, 0, 0, 0},
+		{0, 3, 4, 0},
+	})
+	y := fromSliceOfSlices([][]float64{
+		{0, 1},
+		{2, 0},
+		{0, 0},
+		{1, -0.5},
+	})
+	xy, _ := Multiply(x, y)
+
+	type TestCase struct {
+		desc   string
+		x, y   Matrix
+		format Matrix
+	}
+
+	test_cases := []TestCase{
+		{desc: "CSR x CSR", x: ToCSR(x), y: ToCSR(y), format: &CSR{}},
+		{desc: "CSC x CSC", x: ToCSC(x), y: ToCSC(y), format: &CSC{}},
+		{desc: "CSR x CSC", x: ToCSR(x), y: ToCSC(y), format: &CSR{}},
+		{desc: "CSR x dense", x: ToCSR(x), y: y, format: &Dense{}},
+		{desc: "CSC x dense", x: ToCSC(x), y: y, format: &Dense{}},
+		{desc: "dense x CSC", x: x, y: ToCSC(y), format: &Dense{}},
+		{desc: "dense x CSR", x: x, y: ToCSR(y), format: &Dense{}},
+		{desc: "sparse x CSR", x: ToSparse(x), y: ToCSR(y), format: &Dense{}},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got, err := Multiply(test_case.x, test_case.y)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !Equal(got, xy) {
+				t.Errorf("expected to be the same, got %v, want %v", got, xy)
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(test_case.format) {
+				t.Errorf("expected a %T, got %T", test_case.format, got)
+			}
+		})
+	}
+
+	t.Run("cancelled products are not stored", func(t *testing.T) {
+		a := ToCSR(fromSliceOfSlices([][]float64{{1, 1}}))
+		b := ToCSR(fromSliceOfSlices([][]float64{{1}, {-1}}))
+		got, _ := Multiply(a, b)
+		if n := len(got.(*CSR).Values); n != 0 {
+			t.Errorf("expected no stored values, got %d", n)
+		}
+	})
+
+	t.Run("transpose keeps the format", func(t *testing.T) {
+		for _, c := range []Matrix{ToCSR(x), ToCSC(x)} {
+			got := Transpose(c)
+			if !Equal(got, Transpose(x)) {
+				t.Errorf("expected to be the same, got %v, want %v", got, Transpose(x))
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(c) {
+				t.Errorf("expected a %T, got %T", c, got)
+			}
+		}
+	})
+
+	t.Run("add and scale keep the format", func(t *testing.T) {
+		want, _ := Add(x, Scale(x, -2))
+		for _, c := range []Matrix{ToCSR(x), ToCSC(x)} {
+			got, err := Add(c, Scale(c, -2))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !Equal(got, want) {
+				t.Errorf("expected to be the same, got %v, want %v", got, want)
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(c) {
+				t.Errorf("expected a %T, got %T", c, got)
+			}
+		}
+	})
+}
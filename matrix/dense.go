@@ -0,0 +1,85 @@
+package matrix
+
+import (
+	"fmt"
+	"slices"
+)
+
+/*
+A Dense matrix stores every element contiguously in row major order, so
+`Values[i*M + j]` is the element in row `i` and column `j`. This avoids a hash
+lookup per element, which matters for fully populated matrices such as design
+matrices.
+*/
+type Dense struct {
+	Values []float64
+	// Number of Rows and Columns
+	N, M int
+}
+
+// Create a `n` x `m` dense matrix of zeros
+func NewDense(n, m int) *Dense {
+	return &Dense{
+		Values: make([]float64, n*m),
+		N:      n,
+		M:      m,
+	}
+}
+
+// Create a `n` x `m` dense matrix using (not copying) the row major `values`
+func DenseFrom(n, m int, values []float64) (*Dense, error) {
+	if len(values) != n*m {
+		return nil, fmt.Errorf("Expected %d values for a %d x %d matrix, got %d", n*m, n, m, len(values))
+	}
+	return &Dense{Values: values, N: n, M: m}, nil
+}
+
+func (x *Dense) Dims() (int, int) {
+	return x.N, x.M
+}
+
+// Get the value in a matrix at a point
+func (x *Dense) At(i, j int) float64 {
+	return x.Values[i*x.M+j]
+}
+
+// Set a matrix value at a point
+func (x *Dense) Set(i, j int, v float64) error {
+	if i >= x.N || j >= x.M || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	x.Values[i*x.M+j] = v
+	return nil
+}
+
+// Call `fn` for each non-zero element, in row major order
+func (x *Dense) NonZeros(fn func(i, j int, v float64)) {
+	for k, v := range x.Values {
+		if v != 0 {
+			fn(k/x.M, k%x.M, v)
+		}
+	}
+}
+
+// Add to a value at a point
+func (x *Dense) Update(i, j int, v float64) error {
+	if i >= x.N || j >= x.M || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	x.Values[i*x.M+j] += v
+	return nil
+}
+
+// Copy a matrix in it's entirety
+func (x *Dense) Copy() *Dense {
+	return &Dense{
+		Values: slices.Clone(x.Values),
+		N:      x.N,
+		M:      x.M,
+	}
+}
+
+// Returns row `i` of the matrix, this shares memory with the matrix
+func (x *Dense) Row(i int) []float64 {
+	return x.Values[i*x.M : (i+1)*x.M]
+}
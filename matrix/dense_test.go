@@ -0,0 +1,117 @@
+package matrix
+
+import "testing"
+
+// Helper to build the same matrix sparsely
+func toSparse(x Matrix) *Sparse {
+	n, m := x.Dims()
+	z := NewSparse(n, m)
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			z.Set(i, j, x.At(i, j))
+		}
+	}
+	return z
+}
+
+func TestRepresentation(t *testing.T) {
+	t.Run("zero matrices are dense", func(t *testing.T) {
+		if _, ok := Zero(3, 2).(*Dense); !ok {
+			t.Errorf("expected a dense matrix, got %T", Zero(3, 2))
+		}
+	})
+
+	t.Run("large identities are sparse", func(t *testing.T) {
+		if _, ok := Identity(3).(*Dense); !ok {
+			t.Errorf("expected a dense matrix, got %T", Identity(3))
+		}
+		if _, ok := Identity(100).(*Sparse); !ok {
+			t.Errorf("expected a sparse matrix, got %T", Identity(100))
+		}
+	})
+
+	t.Run("transpose keeps the representation", func(t *testing.T) {
+		x := toSparse(fromSliceOfSlices([][]float64{
+			{1, 0, 2},
+			{0, 3, 0},
+		}))
+		got := Transpose(x)
+		if _, ok := got.(*Sparse); !ok {
+			t.Errorf("expected a sparse matrix, got %T", got)
+		}
+		if !Equal(Transpose(got), x) {
+			t.Errorf("expected to be the same, got %v, want %v", Transpose(got), x)
+		}
+	})
+
+	t.Run("sparse products stay sparse", func(t *testing.T) {
+		x := Identity(100)
+		got, err := Multiply(x, Scale(x, 2))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, ok := got.(*Sparse); !ok {
+			t.Errorf("expected a sparse matrix, got %T", got)
+		}
+		if !Equal(got, Scale(Identity(100), 2)) {
+			t.Errorf("expected 2I")
+		}
+	})
+
+	t.Run("the same results for all representations", func(t *testing.T) {
+		x := fromSliceOfSlices([][]float64{
+			{3.2, 3.0, 2.9},
+			{0.3, 1.23, 83.3},
+			{58.2, 12.1, 100},
+		})
+		y := fromSliceOfSlices([][]float64{
+			{1, 0},
+			{2.5, -1},
+			{0, 4},
+		})
+		want, _ := Multiply(x, y)
+
+		for _, pair := range [][2]Matrix{{toSparse(x), y}, {x, toSparse(y)}, {toSparse(x), toSparse(y)}} {
+			got, err := Multiply(pair[0], pair[1])
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !Equal(got, want) {
+				t.Errorf("%T x %T: got %v, want %v", pair[0], pair[1], got, want)
+			}
+		}
+
+		sum, _ := Add(toSparse(x), toSparse(x))
+		if !Equal(sum, Scale(x, 2)) {
+			t.Errorf("expected x + x = 2x, got %v", sum)
+		}
+	})
+}
+
+func TestDense(t *testing.T) {
+	t.Run("values are row major", func(t *testing.T) {
+		z, err := DenseFrom(2, 3, []float64{1, 2, 3, 4, 5, 6})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if z.At(1, 0) != 4 || z.At(0, 2) != 3 {
+			t.Errorf("unexpected layout %v", z.Values)
+		}
+	})
+
+	t.Run("fail on the wrong number of values", func(t *testing.T) {
+		if _, err := DenseFrom(2, 2, []float64{1, 2, 3}); err == nil {
+			t.Errorf("expected DenseFrom to fail")
+		}
+	})
+
+	t.Run("fail on setting out of range", func(t *testing.T) {
+		z := NewDense(2, 2)
+		if err := z.Set(2, 0, 1); err == nil {
+			t.Errorf("expected Set to fail")
+		}
+		if err := z.Set(0, -1, 1); err == nil {
+			t.Errorf("expected Set to fail")
+		}
+	})
+}
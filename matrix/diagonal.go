@@ -0,0 +1,58 @@
+package matrix
+
+import (
+	"fmt"
+	"slices"
+)
+
+// A square matrix which is zero off the diagonal, only the diagonal is stored
+type Diagonal struct {
+	Values []float64
+}
+
+// Create a diagonal matrix with `values` along the diagonal
+func NewDiagonal(values []float64) *Diagonal {
+	return &Diagonal{Values: values}
+}
+
+func (x *Diagonal) Dims() (int, int) {
+	return len(x.Values), len(x.Values)
+}
+
+// Get the value in a matrix at a point
+func (x *Diagonal) At(i, j int) float64 {
+	if i != j {
+		return 0
+	}
+	return x.Values[i]
+}
+
+// Set a matrix value at a point, only the diagonal can be non-zero
+func (x *Diagonal) Set(i, j int, v float64) error {
+	n := len(x.Values)
+	if i >= n || j >= n || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	if i != j {
+		if v != 0 {
+			return fmt.Errorf("Can't set (%d, %d) of a diagonal matrix", i, j)
+		}
+		return nil
+	}
+	x.Values[i] = v
+	return nil
+}
+
+// Call `fn` for each non-zero element, in row major order
+func (x *Diagonal) NonZeros(fn func(i, j int, v float64)) {
+	for i, v := range x.Values {
+		if v != 0 {
+			fn(i, i, v)
+		}
+	}
+}
+
+// Copy a matrix in it's entirety
+func (x *Diagonal) Copy() *Diagonal {
+	return &Diagonal{Values: slices.Clone(x.Values)}
+}
@@ -0,0 +1,142 @@
+package matrix
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+/*
+The eigendecomposition of a symmetric `N` x `N` matrix, `A = V D V'` where the
+eigenvalues on the diagonal of D are real and decreasing, and the columns of V
+are orthonormal eigenvectors.
+
+It is computed by cyclic Jacobi rotations, each of which zeroes an
+off-diagonal pair, until A is diagonal to working precision.
+*/
+type SymmetricEigen struct {
+	values  []float64
+	vectors [][]float64 // Columns of V
+	N       int
+}
+
+// Decompose `x` into V D V'. Only the lower triangle of `x` is read, so it is
+// assumed to be symmetric.
+func DecomposeSymmetricEigen(x Matrix) (SymmetricEigen, error) {
+	if b, err := isSquare(x); !b {
+		return SymmetricEigen{}, err
+	}
+	n, _ := x.Dims()
+
+	a := make([][]float64, n)
+	v := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, n)
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			a[i][j] = x.At(i, j)
+			a[j][i] = a[i][j]
+		}
+	}
+
+	// The largest diagonal element at the start, to judge when an off-diagonal
+	// element is negligible
+	scale := 0.0
+	for i := 0; i < n; i++ {
+		scale = max(scale, math.Abs(a[i][i]))
+	}
+
+	converged := false
+	for sweep := 0; sweep < maxSweeps && !converged; sweep++ {
+		// Stop after a sweep without any rotations. Rounding leaves the
+		// off-diagonal around epsilon ||A||, so rather than waiting for it to
+		// vanish, elements small against their diagonal pair are set to zero.
+		converged = true
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := math.Abs(a[p][q])
+				if apq <= epsilon*math.Sqrt(math.Abs(a[p][p])*math.Abs(a[q][q])) || apq <= epsilon*epsilon*scale {
+					a[p][q], a[q][p] = 0, 0
+					continue
+				}
+				converged = false
+
+				// The rotation which zeroes a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(1+theta*theta))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+
+				// A J, then J' (A J), and V J
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				rotate(a[p], a[q], c, s)
+				rotate(v[p], v[q], c, s)
+				a[p][q], a[q][p] = 0, 0 // Zero up to rounding, so make it exact
+			}
+		}
+	}
+	if !converged {
+		return SymmetricEigen{}, fmt.Errorf("Eigendecomposition did not converge in %d sweeps", maxSweeps)
+	}
+
+	order := make([]int, n)
+	for j := range order {
+		order[j] = j
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return cmp.Compare(a[j][j], a[i][i]) })
+
+	f := SymmetricEigen{values: make([]float64, n), vectors: make([][]float64, n), N: n}
+	for k, j := range order {
+		f.values[k], f.vectors[k] = a[j][j], v[j]
+	}
+	return f, nil
+}
+
+// Returns the eigenvalues in decreasing order
+func (f SymmetricEigen) Values() []float64 {
+	return slices.Clone(f.values)
+}
+
+// Returns the orthonormal eigenvectors as the columns of an `N` x `N` matrix,
+// in the same order as the eigenvalues
+func (f SymmetricEigen) Vectors() Matrix {
+	return fromColumns(f.N, f.vectors)
+}
+
+// Returns the 2-norm condition number, the ratio of the largest to the
+// smallest absolute eigenvalue. This is infinite for singular matrices.
+func (f SymmetricEigen) Cond() float64 {
+	if f.N == 0 {
+		return 0
+	}
+	biggest, smallest := 0.0, math.Inf(1)
+	for _, v := range f.values {
+		biggest = max(biggest, math.Abs(v))
+		smallest = min(smallest, math.Abs(v))
+	}
+	if smallest == 0 {
+		return math.Inf(1)
+	}
+	return biggest / smallest
+}
+
+// Whether all of the eigenvalues are positive
+func (f SymmetricEigen) IsPositiveDefinite() bool {
+	for _, v := range f.values {
+		if v <= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecomposeSymmetricEigen(t *testing.T) {
+	x := benchDesign(20, 4)
+	xtx, _ := Multiply(Transpose(x), x)
+
+	// Larger problems, where rounding stops the off-diagonal from vanishing
+	wide := benchDesign(200, 50)
+	wideXtx, _ := Multiply(Transpose(wide), wide)
+	random := benchDesign(40, 40)
+	symmetric, _ := Add(random, Transpose(random))
+	collinear := NewDense(100, 30)
+	for i := 0; i < 100; i++ {
+		for j := 0; j < 30; j++ {
+			collinear.Set(i, j, wide.At(i, j%10)) // Rank 10
+		}
+	}
+	collinearXtx, _ := Multiply(Transpose(collinear), collinear)
+
+	type TestCase struct {
+		desc   string
+		input  Matrix
+		values []float64
+	}
+
+	test_cases := []TestCase{
+		{
+			desc: "eigenvalues of a 2x2 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{2, 1},
+				{1, 2},
+			}),
+			values: []float64{3, 1},
+		},
+		{
+			desc: "eigenvalues of an indefinite 3x3 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 2, 0},
+				{2, 1, 0},
+				{0, 0, -2},
+			}),
+			values: []float64{3, -1, -2},
+		},
+		{
+			desc:   "eigenvalues of a diagonal matrix",
+			input:  NewDiagonal([]float64{1, 5, -3}),
+			values: []float64{5, 1, -3},
+		},
+		{
+			desc:  "eigenvalues of X'X",
+			input: xtx,
+		},
+		{
+			desc:  "eigenvalues of X'X with 50 columns",
+			input: wideXtx,
+		},
+		{
+			desc:  "eigenvalues of a 40x40 symmetric matrix",
+			input: symmetric,
+		},
+		{
+			desc:  "eigenvalues of a singular X'X",
+			input: collinearXtx,
+		},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			eig, err := DecomposeSymmetricEigen(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			values := eig.Values()
+			if test_case.values != nil && !near(fromSliceOfSlices([][]float64{values}), fromSliceOfSlices([][]float64{test_case.values}), 1e-12) {
+				t.Errorf("expected to be the same, got %v, want %v", values, test_case.values)
+			}
+			for k := 1; k < len(values); k++ {
+				if values[k] > values[k-1] {
+					t.Errorf("expected decreasing eigenvalues, got %v", values)
+				}
+			}
+
+			v := eig.Vectors()
+			if !isOrthonormal(v) {
+				t.Errorf("expected orthonormal eigenvectors, got %v", v)
+			}
+			av, _ := Multiply(test_case.input, v)
+			vd, _ := Multiply(v, NewDiagonal(values))
+			if !near(av, vd, 1e-10) {
+				t.Errorf("expected AV = VD, got %v, want %v", av, vd)
+			}
+		})
+	}
+
+	t.Run("eigenvalues of X'X are the squared singular values of X", func(t *testing.T) {
+		eig, _ := DecomposeSymmetricEigen(xtx)
+		svd, _ := DecomposeSVD(x)
+		for k, s := range svd.Values() {
+			if got := eig.Values()[k]; math.Abs(got-s*s) > 1e-10*s*s {
+				t.Errorf("expected to be the same, got %v, want %v", got, s*s)
+			}
+		}
+		if got, want := eig.Cond(), svd.Cond()*svd.Cond(); math.Abs(got-want) > 1e-9*want {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if !eig.IsPositiveDefinite() {
+			t.Errorf("expected X'X to be positive definite")
+		}
+	})
+
+	t.Run("indefinite and singular matrices", func(t *testing.T) {
+		eig, _ := DecomposeSymmetricEigen(fromSliceOfSlices([][]float64{{1, 2}, {2, 1}}))
+		if eig.IsPositiveDefinite() {
+			t.Errorf("expected an indefinite matrix")
+		}
+		eig, _ = DecomposeSymmetricEigen(fromSliceOfSlices([][]float64{{1, 1}, {1, 1}}))
+		if got := eig.Cond(); got < 1e15 {
+			t.Errorf("expected a huge condition number, got %v", got)
+		}
+	})
+
+	t.Run("fail on non-square matrices", func(t *testing.T) {
+		if _, err := DecomposeSymmetricEigen(Zero(2, 3)); err == nil {
+			t.Errorf("expected the decomposition to fail")
+		}
+	})
+}
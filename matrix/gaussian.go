@@ -1,63 +1,234 @@
 package matrix
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 // Swap two rows
 func SwapRows(x Matrix, row1, row2 int) (Matrix, error) {
-	if row1 > x.N {
-		return Matrix{}, fmt.Errorf("row1 out of range. %d > %d", row1, x.N)
+	z := editableCopy(x)
+	return z, SwapRowsInPlace(z, row1, row2)
+}
+
+// Scale a row by a factor
+func ScaleRow(x Matrix, row int, scale float64) (Matrix, error) {
+	z := editableCopy(x)
+	return z, ScaleRowInPlace(z, row, scale)
+}
+
+// Add a multiple of one row to the other
+func AddToRow(x Matrix, row1, row2 int, scale float64) (Matrix, error) {
+	z := editableCopy(x)
+	return z, AddToRowInPlace(z, row1, row2, scale)
+}
+
+// Swap two columns
+func SwapColumns(x Matrix, col1, col2 int) (Matrix, error) {
+	z := editableCopy(x)
+	return z, SwapColumnsInPlace(z, col1, col2)
+}
+
+// Scale a column by a factor
+func ScaleColumn(x Matrix, col int, scale float64) (Matrix, error) {
+	z := editableCopy(x)
+	return z, ScaleColumnInPlace(z, col, scale)
+}
+
+// Add a multiple of one column to the other
+func AddToColumn(x Matrix, col1, col2 int, scale float64) (Matrix, error) {
+	z := editableCopy(x)
+	return z, AddToColumnInPlace(z, col1, col2, scale)
+}
+
+// Copy `x` into a representation which any element can be set in
+func editableCopy(x Matrix) Matrix {
+	switch x.(type) {
+	case *Diagonal, *Triangular:
+		n, m := x.Dims()
+		return fromDense(n, m, toDense(x))
 	}
-	if row2 > x.N {
-		return Matrix{}, fmt.Errorf("row2 out of range. %d > %d", row2, x.N)
+	return Copy(x)
+}
+
+// Helper for consistent error messaging
+func checkIndex(name string, i, n int) error {
+	if i < 0 || i >= n {
+		return fmt.Errorf("%s out of range. %d >= %d", name, i, n)
 	}
+	return nil
+}
 
-	// Implemented via matrix multiplication - less efficient but should be OK
-	z := Identity(x.N)
+// Swap two rows of `x` in place
+func SwapRowsInPlace(x Matrix, row1, row2 int) error {
+	n, m := x.Dims()
+	if err := checkIndex("row1", row1, n); err != nil {
+		return err
+	}
+	if err := checkIndex("row2", row2, n); err != nil {
+		return err
+	}
+	if row1 == row2 {
+		return nil
+	}
 
-	z.Set(row1, row1, 0)
-	z.Set(row2, row2, 0)
+	if x, ok := x.(*Dense); ok {
+		r1, r2 := x.Row(row1), x.Row(row2)
+		for j := range r1 {
+			r1[j], r2[j] = r2[j], r1[j]
+		}
+		return nil
+	}
+	for j := 0; j < m; j++ {
+		a, b := x.At(row1, j), x.At(row2, j)
+		if err := x.Set(row1, j, b); err != nil {
+			return err
+		}
+		if err := x.Set(row2, j, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	z.Set(row1, row2, 1)
-	z.Set(row2, row1, 1)
+// Scale a row of `x` by a factor in place
+func ScaleRowInPlace(x Matrix, row int, scale float64) error {
+	n, m := x.Dims()
+	if err := checkIndex("row", row, n); err != nil {
+		return err
+	}
 
-	return Multiply(z, x)
+	if x, ok := x.(*Dense); ok {
+		r := x.Row(row)
+		for j := range r {
+			r[j] *= scale
+		}
+		return nil
+	}
+	for j := 0; j < m; j++ {
+		if v := x.At(row, j); v != 0 {
+			if err := x.Set(row, j, v*scale); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-// Scale a row by a factor
-func ScaleRow(x Matrix, row int, scale float64) (Matrix, error) {
-	if row > x.N {
-		return Matrix{}, fmt.Errorf("row1 out of range. %d > %d", row, x.N)
+// Add `scale` times row2 to row1 of `x` in place
+func AddToRowInPlace(x Matrix, row1, row2 int, scale float64) error {
+	n, m := x.Dims()
+	if err := checkIndex("row1", row1, n); err != nil {
+		return err
+	}
+	if err := checkIndex("row2", row2, n); err != nil {
+		return err
 	}
 
-	// Implemented via matrix multiplication - less efficient but should be OK
-	z := Identity(x.N)
+	if x, ok := x.(*Dense); ok {
+		r1, r2 := x.Row(row1), x.Row(row2)
+		for j, v := range r2 {
+			r1[j] += scale * v
+		}
+		return nil
+	}
+	for j := 0; j < m; j++ {
+		if v := x.At(row2, j); v != 0 {
+			if err := x.Set(row1, j, x.At(row1, j)+scale*v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-	z.Set(row, row, scale)
+// Swap two columns of `x` in place
+func SwapColumnsInPlace(x Matrix, col1, col2 int) error {
+	n, m := x.Dims()
+	if err := checkIndex("col1", col1, m); err != nil {
+		return err
+	}
+	if err := checkIndex("col2", col2, m); err != nil {
+		return err
+	}
+	if col1 == col2 {
+		return nil
+	}
 
-	return Multiply(z, x)
+	if x, ok := x.(*Dense); ok {
+		for i := 0; i < n; i++ {
+			r := x.Row(i)
+			r[col1], r[col2] = r[col2], r[col1]
+		}
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		a, b := x.At(i, col1), x.At(i, col2)
+		if err := x.Set(i, col1, b); err != nil {
+			return err
+		}
+		if err := x.Set(i, col2, a); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Add a multiple of one row to the other
-func AddToRow(x Matrix, row1, row2 int, scale float64) (Matrix, error) {
-	if row1 > x.N {
-		return Matrix{}, fmt.Errorf("row1 out of range. %d > %d", row1, x.N)
-	}
-	if row2 > x.N {
-		return Matrix{}, fmt.Errorf("row2 out of range. %d > %d", row2, x.N)
+// Scale a column of `x` by a factor in place
+func ScaleColumnInPlace(x Matrix, col int, scale float64) error {
+	n, m := x.Dims()
+	if err := checkIndex("col", col, m); err != nil {
+		return err
 	}
 
-	// Implemented via matrix multiplication - less efficient but should be OK
-	z := Identity(x.N)
+	if x, ok := x.(*Dense); ok {
+		for i := 0; i < n; i++ {
+			x.Values[i*m+col] *= scale
+		}
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		if v := x.At(i, col); v != 0 {
+			if err := x.Set(i, col, v*scale); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-	z.Set(row1, row2, scale)
+// Add `scale` times col2 to col1 of `x` in place
+func AddToColumnInPlace(x Matrix, col1, col2 int, scale float64) error {
+	n, m := x.Dims()
+	if err := checkIndex("col1", col1, m); err != nil {
+		return err
+	}
+	if err := checkIndex("col2", col2, m); err != nil {
+		return err
+	}
 
-	return Multiply(z, x)
+	if x, ok := x.(*Dense); ok {
+		for i := 0; i < n; i++ {
+			r := x.Row(i)
+			r[col1] += scale * r[col2]
+		}
+		return nil
+	}
+	for i := 0; i < n; i++ {
+		if v := x.At(i, col2); v != 0 {
+			if err := x.Set(i, col1, x.At(i, col1)+scale*v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // Return a matrix in echelon form alongside a permutation matrix
 func GaussianElimination(x Matrix) (Matrix, Matrix, error) {
-	z := x.Copy()
-	p := Identity(x.N) // Permutation matrix
+	n, m := x.Dims()
+	z := fromDense(n, m, toDense(x)) // Dense, so the row operations are in place and O(m)
+	p := denseIdentity(n)            // Permutation matrix
 
 	// Current Row
 	i := 0
@@ -66,33 +237,30 @@ func GaussianElimination(x Matrix) (Matrix, Matrix, error) {
 
 	for {
 		// Check to see if we actually need to do anything
-		if b, _ := IsUpperTriangular(z); b {
+		if isUpperTrapezoidal(z) {
 			return z, p, nil
 		}
 		// If we are past x.M/x.N
-		if i >= x.N {
+		if i >= n {
 			return z, p, nil
 		}
-		if j >= x.M {
+		if j >= m {
 			return z, p, nil
 		}
 
 		// Get pivot if unable to
-		if z.Get(i, j) == 0 {
-			for k := i; k <= x.N; k++ {
-				if k == x.N {
+		if z.At(i, j) == 0 {
+			for k := i; k <= n; k++ {
+				if k == n {
 					j += 1 // Overflowerd rows, meaning all zero, move to next column
 					break  // Kick us to the continue
 				}
-				if z.Get(k, j) != 0 {
-					var err error
-					z, err = SwapRows(z, i, k)
-					if err != nil {
-						return Matrix{}, Matrix{}, err
+				if z.At(k, j) != 0 {
+					if err := SwapRowsInPlace(z, i, k); err != nil {
+						return nil, nil, err
 					}
-					p, err = SwapRows(p, i, k) // We need to track permutations too
-					if err != nil {
-						return Matrix{}, Matrix{}, err
+					if err := SwapRowsInPlace(p, i, k); err != nil { // We need to track permutations too
+						return nil, nil, err
 					}
 					break
 				}
@@ -101,17 +269,126 @@ func GaussianElimination(x Matrix) (Matrix, Matrix, error) {
 		}
 
 		// Make all other column entries zero
-		for k := i + 1; k < x.N; k++ {
-			var err error
-			scale := -(z.Get(k, j) / z.Get(i, j))
-			z, err = AddToRow(z, k, i, scale) // Add row scaled row i to row k
-			if err != nil {
-				return Matrix{}, Matrix{}, err
+		for k := i + 1; k < n; k++ {
+			scale := -(z.At(k, j) / z.At(i, j))
+			if err := AddToRowInPlace(z, k, i, scale); err != nil { // Add row scaled row i to row k
+				return nil, nil, err
 			}
 		}
 
 		i += 1 // Move onto the next row to reduce
 
-		z.fuzzCheck() // Make any 'almost zeros' zero
+		fuzzCheck(z) // Make any 'almost zeros' zero
+	}
+}
+
+// Whether `x` is zero below its diagonal, like IsUpperTriangular but for any shape
+func isUpperTrapezoidal(x Matrix) bool {
+	upper := true
+	x.NonZeros(func(i, j int, v float64) {
+		if i > j {
+			upper = false
+		}
+	})
+	return upper
+}
+
+// Returns the reduced row echelon form of matrix `x`, of any shape, and its
+// pivot columns. Each pivot is 1 and the only non-zero in its column. Pivots
+// are chosen as the largest in their column, and a column with nothing bigger
+// than a tolerance left is taken to depend on the earlier ones.
+//
+// The pivot columns of `x` are linearly independent and the others are
+// combinations of them, so len(pivots) is the rank. Rank by SVD is more
+// reliable for columns which are nearly dependent.
+func RREF(x Matrix) (Matrix, []int) {
+	n, m := x.Dims()
+	z := fromDense(n, m, toDense(x)).(*Dense)
+
+	// As MATLAB's rref, relative to the infinity norm
+	tol := float64(max(n, m)) * epsilon * NormInf(z)
+
+	var pivots []int
+	i := 0
+	for j := 0; j < m && i < n; j++ {
+		p := i
+		for k := i + 1; k < n; k++ {
+			if math.Abs(z.At(k, j)) > math.Abs(z.At(p, j)) {
+				p = k
+			}
+		}
+		if math.Abs(z.At(p, j)) <= tol {
+			for k := i; k < n; k++ {
+				z.Set(k, j, 0)
+			}
+			continue
+		}
+		pivots = append(pivots, j)
+
+		SwapRowsInPlace(z, i, p)
+		ScaleRowInPlace(z, i, 1/z.At(i, j))
+		for k := 0; k < n; k++ {
+			if k != i && z.At(k, j) != 0 {
+				AddToRowInPlace(z, k, i, -z.At(k, j))
+				z.Set(k, j, 0)
+			}
+		}
+		z.Set(i, j, 1)
+		i++
+	}
+
+	return z, pivots
+}
+
+// Returns a basis for the null space of matrix `x` as the columns of a matrix,
+// the vectors v with xv = 0. There is one for each non-pivot column j of the
+// RREF, with a 1 in row j, and so expresses column j of `x` in terms of the
+// pivot columns.
+func NullSpace(x Matrix) Matrix {
+	_, m := x.Dims()
+	r, pivots := RREF(x)
+
+	isPivot := make([]bool, m)
+	for _, j := range pivots {
+		isPivot[j] = true
+	}
+	var free []int
+	for j := 0; j < m; j++ {
+		if !isPivot[j] {
+			free = append(free, j)
+		}
+	}
+
+	z := NewDense(m, len(free))
+	for c, j := range free {
+		z.Set(j, c, 1)
+		for k, p := range pivots {
+			z.Set(p, c, -r.At(k, j))
+		}
+	}
+	return z
+}
+
+// Returns a basis for the column space of matrix `x` as the columns of a
+// matrix, the pivot columns of `x` itself
+func ColumnSpace(x Matrix) Matrix {
+	n, _ := x.Dims()
+	_, pivots := RREF(x)
+
+	z := NewDense(n, len(pivots))
+	for c, j := range pivots {
+		for i := 0; i < n; i++ {
+			z.Set(i, c, x.At(i, j))
+		}
+	}
+	return z
+}
+
+// Returns the `n` x `n` identity as a Dense matrix, to be changed in place
+func denseIdentity(n int) *Dense {
+	z := NewDense(n, n)
+	for i := 0; i < n; i++ {
+		z.Values[i*n+i] = 1
 	}
+	return z
 }
@@ -1,6 +1,10 @@
 package matrix
 
-import "testing"
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
 
 func TestSwapRow(t *testing.T) {
 	type TestCase struct {
@@ -131,6 +135,122 @@ func TestAddToRow(t *testing.T) {
 	}
 }
 
+func TestRowOperationsInPlace(t *testing.T) {
+	input := fromSliceOfSlices([][]float64{
+		{1.4, 4.4},
+		{3.2, 0},
+		{2.9, 9.3},
+	})
+	want := fromSliceOfSlices([][]float64{
+		{5.8, 18.6},
+		{-0.3, 9.3},
+		{1.4, 4.4},
+	})
+
+	for _, x := range []Matrix{Copy(input), toSparse(input), ToCSR(input)} {
+		t.Run(fmt.Sprintf("%T", x), func(t *testing.T) {
+			if err := SwapRowsInPlace(x, 0, 2); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err := ScaleRowInPlace(x, 0, 2); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err := AddToRowInPlace(x, 1, 0, -0.5); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err := ScaleRowInPlace(x, 1, -1); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !near(x, want, 1e-12) {
+				t.Errorf("expected to be the same, got %v, want %v", x, want)
+			}
+		})
+	}
+
+	t.Run("the copying operations leave their input alone", func(t *testing.T) {
+		x := Copy(input)
+		SwapRows(x, 0, 1)
+		ScaleRow(x, 0, 2)
+		AddToRow(x, 0, 1, 2)
+		if !Equal(x, input) {
+			t.Errorf("expected to be the same, got %v, want %v", x, input)
+		}
+	})
+
+	t.Run("structured matrices are copied densely", func(t *testing.T) {
+		got, err := SwapRows(NewDiagonal([]float64{1, 2}), 0, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if want := fromSliceOfSlices([][]float64{{0, 2}, {1, 0}}); !Equal(got, want) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("fail on rows out of range", func(t *testing.T) {
+		if err := SwapRowsInPlace(Copy(input), 0, 3); err == nil {
+			t.Errorf("expected an error for a row out of range")
+		}
+		if err := ScaleRowInPlace(Copy(input), -1, 2); err == nil {
+			t.Errorf("expected an error for a row out of range")
+		}
+		if err := AddToRowInPlace(Copy(input), 5, 0, 1); err == nil {
+			t.Errorf("expected an error for a row out of range")
+		}
+	})
+}
+
+func TestColumnOperations(t *testing.T) {
+	input := fromSliceOfSlices([][]float64{
+		{1.4, 4.4, 0},
+		{3.2, 2.0, 1},
+	})
+
+	// Each column operation is the row operation on the transpose
+	for _, x := range []Matrix{input, toSparse(input)} {
+		t.Run(fmt.Sprintf("%T", x), func(t *testing.T) {
+			got, err := SwapColumns(x, 0, 2)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			want, _ := SwapRows(Transpose(x), 0, 2)
+			if !Equal(got, Transpose(want)) {
+				t.Errorf("expected to be the same, got %v, want %v", got, Transpose(want))
+			}
+
+			got, err = ScaleColumn(x, 1, -3)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			want, _ = ScaleRow(Transpose(x), 1, -3)
+			if !Equal(got, Transpose(want)) {
+				t.Errorf("expected to be the same, got %v, want %v", got, Transpose(want))
+			}
+
+			got, err = AddToColumn(x, 2, 0, 0.5)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			want, _ = AddToRow(Transpose(x), 2, 0, 0.5)
+			if !Equal(got, Transpose(want)) {
+				t.Errorf("expected to be the same, got %v, want %v", got, Transpose(want))
+			}
+		})
+	}
+
+	t.Run("fail on columns out of range", func(t *testing.T) {
+		if _, err := SwapColumns(input, 0, 3); err == nil {
+			t.Errorf("expected an error for a column out of range")
+		}
+		if err := ScaleColumnInPlace(Copy(input), 3, 2); err == nil {
+			t.Errorf("expected an error for a column out of range")
+		}
+		if err := AddToColumnInPlace(Copy(input), 0, -1, 1); err == nil {
+			t.Errorf("expected an error for a column out of range")
+		}
+	})
+}
+
 func TestGuassianElimination(t *testing.T) {
 	type TestCase struct {
 		desc        string
@@ -164,6 +284,22 @@ func TestGuassianElimination(t *testing.T) {
 				{0, 0, 0, 0},
 			}),
 		},
+
+		{
+			desc: "Gaussian elimination in a 4x2 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{0, 1},
+				{2, 4},
+				{1, 3},
+				{4, 8},
+			}),
+			want: fromSliceOfSlices([][]float64{
+				{2, 4},
+				{0, 1},
+				{0, 0},
+				{0, 0},
+			}),
+		},
 	}
 
 	for _, test_case := range test_cases {
@@ -180,3 +316,104 @@ func TestGuassianElimination(t *testing.T) {
 		})
 	}
 }
+
+func TestRREF(t *testing.T) {
+	type TestCase struct {
+		desc   string
+		input  Matrix
+		want   Matrix
+		pivots []int
+	}
+
+	test_cases := []TestCase{
+		{
+			desc: "RREF of an invertible matrix is the identity",
+			input: fromSliceOfSlices([][]float64{
+				{2, 1, -1},
+				{-3, -1, 2},
+				{-2, 1, 2},
+			}),
+			want:   Identity(3),
+			pivots: []int{0, 1, 2},
+		},
+		{
+			desc: "RREF of a wide matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 3, 1, 9},
+				{1, 1, -1, 1},
+				{3, 11, 5, 35},
+			}),
+			want: fromSliceOfSlices([][]float64{
+				{1, 0, -2, -3},
+				{0, 1, 1, 4},
+				{0, 0, 0, 0},
+			}),
+			pivots: []int{0, 1},
+		},
+		{
+			desc: "RREF of a tall design with a collinear column",
+			input: fromSliceOfSlices([][]float64{
+				{1, 1, 2},
+				{1, 2, 4},
+				{1, 3, 6},
+				{1, 4, 8},
+			}),
+			want: fromSliceOfSlices([][]float64{
+				{1, 0, 0},
+				{0, 1, 2},
+				{0, 0, 0},
+				{0, 0, 0},
+			}),
+			pivots: []int{0, 1},
+		},
+		{
+			desc:   "RREF of a zero matrix",
+			input:  NewDense(2, 3),
+			want:   NewDense(2, 3),
+			pivots: nil,
+		},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got, pivots := RREF(test_case.input)
+			if !near(got, test_case.want, 1e-12) {
+				t.Errorf("expected to be the same, got %v, want %v", got, test_case.want)
+			}
+			if !slices.Equal(pivots, test_case.pivots) {
+				t.Errorf("expected to be the same, got %v, want %v", pivots, test_case.pivots)
+			}
+
+			// The null space is annihilated by x, and with the column space spans everything
+			n, m := test_case.input.Dims()
+			null := NullSpace(test_case.input)
+			if _, k := null.Dims(); k != m-len(test_case.pivots) {
+				t.Errorf("expected a null space of dimension %d, got %d", m-len(test_case.pivots), k)
+			}
+			zero, _ := Multiply(test_case.input, null)
+			if !near(zero, NewDense(n, m-len(test_case.pivots)), 1e-12) {
+				t.Errorf("expected x times its null space to be zero, got %v", zero)
+			}
+			cols := ColumnSpace(test_case.input)
+			if _, k := cols.Dims(); k != len(test_case.pivots) {
+				t.Errorf("expected a column space of dimension %d, got %d", len(test_case.pivots), k)
+			}
+			if rank, _ := Rank(test_case.input); rank != len(test_case.pivots) {
+				t.Errorf("expected the rank to be the number of pivots, got %d, want %d", rank, len(test_case.pivots))
+			}
+		})
+	}
+
+	t.Run("the null space of a collinear design names the redundant column", func(t *testing.T) {
+		// Column 2 is twice column 1
+		x := fromSliceOfSlices([][]float64{
+			{1, 1, 2},
+			{1, 2, 4},
+			{1, 3, 6},
+		})
+		want := fromSliceOfSlices([][]float64{{0}, {-2}, {1}})
+		if got := NullSpace(x); !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,607 @@
+package matrix
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+// Returns the upper Hessenberg form H of square matrix `x`, which is zero
+// below the first subdiagonal, and the orthogonal Q with `x` = Q H Q'. This is
+// the first step of finding eigenvalues, it keeps them but makes each QR
+// iteration O(n^2) rather than O(n^3).
+func Hessenberg(x Matrix) (Matrix, Matrix, error) {
+	if b, err := isSquare(x); !b {
+		return nil, nil, err
+	}
+	h, q := hessenberg(x, true)
+	n := len(h)
+	return fromRows(n, h), fromRows(n, q), nil
+}
+
+// Reduce `x` to Hessenberg form by Householder similarity transformations,
+// accumulating them into Q if `wantQ` (EISPACK's orthes)
+func hessenberg(x Matrix, wantQ bool) ([][]float64, [][]float64) {
+	n, _ := x.Dims()
+	h := make([][]float64, n)
+	for i := range h {
+		h[i] = make([]float64, n)
+	}
+	x.NonZeros(func(i, j int, v float64) {
+		h[i][j] = v
+	})
+
+	ort := make([]float64, n)
+	for m := 1; m < n-1; m++ {
+		// Scale the column to avoid under/overflow
+		scale := 0.0
+		for i := m; i < n; i++ {
+			scale += math.Abs(h[i][m-1])
+		}
+		if scale == 0 {
+			continue
+		}
+
+		// The Householder vector which zeroes below h[m][m-1]
+		s := 0.0
+		for i := n - 1; i >= m; i-- {
+			ort[i] = h[i][m-1] / scale
+			s += ort[i] * ort[i]
+		}
+		g := math.Sqrt(s)
+		if ort[m] > 0 {
+			g = -g
+		}
+		s -= ort[m] * g
+		ort[m] -= g
+
+		// H = (I - u u'/s) H (I - u u'/s)
+		for j := m; j < n; j++ {
+			f := 0.0
+			for i := n - 1; i >= m; i-- {
+				f += ort[i] * h[i][j]
+			}
+			f /= s
+			for i := m; i < n; i++ {
+				h[i][j] -= f * ort[i]
+			}
+		}
+		for i := 0; i < n; i++ {
+			f := 0.0
+			for j := n - 1; j >= m; j-- {
+				f += ort[j] * h[i][j]
+			}
+			f /= s
+			for j := m; j < n; j++ {
+				h[i][j] -= f * ort[j]
+			}
+		}
+		ort[m] *= scale
+		h[m][m-1] = scale * g
+	}
+
+	var q [][]float64
+	if wantQ {
+		q = make([][]float64, n)
+		for i := range q {
+			q[i] = make([]float64, n)
+			q[i][i] = 1
+		}
+		for m := n - 2; m >= 1; m-- {
+			if h[m][m-1] == 0 {
+				continue
+			}
+			for i := m + 1; i < n; i++ {
+				ort[i] = h[i][m-1]
+			}
+			for j := m; j < n; j++ {
+				g := 0.0
+				for i := m; i < n; i++ {
+					g += ort[i] * q[i][j]
+				}
+				// Double division avoids possible underflow
+				g = (g / ort[m]) / h[m][m-1]
+				for i := m; i < n; i++ {
+					q[i][j] += g * ort[i]
+				}
+			}
+		}
+	}
+
+	// Below the subdiagonal is left holding the Householder vectors
+	for i := 2; i < n; i++ {
+		for j := 0; j < i-1; j++ {
+			h[i][j] = 0
+		}
+	}
+	return h, q
+}
+
+// Create an `n` x `n` matrix from its rows
+func fromRows(n int, rows [][]float64) Matrix {
+	z := NewDense(n, n)
+	for i, row := range rows {
+		copy(z.Row(i), row)
+	}
+	return z
+}
+
+/*
+The eigenvalues, and optionally eigenvectors, of a general square `N` x `N`
+matrix. Eigenvalues of a real matrix are real or come in complex conjugate
+pairs, so they are complex128.
+
+The matrix is reduced to Hessenberg form then to real Schur form by the
+Francis double shift QR algorithm, following EISPACK's hqr2.
+*/
+type Eigen struct {
+	values  []complex128
+	vectors [][]complex128
+	N       int
+}
+
+// Most QR iterations to find any one eigenvalue before giving up
+const maxQRIterations = 100
+
+// Find the eigenvalues of `x`, and its eigenvectors if `vectors`
+func DecomposeEigen(x Matrix, vectors bool) (Eigen, error) {
+	if b, err := isSquare(x); !b {
+		return Eigen{}, err
+	}
+	h, v := hessenberg(x, vectors)
+	d, e, err := schur(h, v)
+	if err != nil {
+		return Eigen{}, err
+	}
+
+	nn := len(h)
+	f := Eigen{values: make([]complex128, nn), N: nn}
+	for i := range d {
+		f.values[i] = complex(d[i], e[i])
+	}
+	if vectors {
+		// A complex pair has its real and imaginary parts in adjacent columns of V
+		f.vectors = make([][]complex128, nn)
+		for j := 0; j < nn; j++ {
+			z := make([]complex128, nn)
+			for i := range z {
+				switch {
+				case e[j] > 0:
+					z[i] = complex(v[i][j], v[i][j+1])
+				case e[j] < 0:
+					z[i] = complex(v[i][j-1], -v[i][j])
+				default:
+					z[i] = complex(v[i][j], 0)
+				}
+			}
+			// Scale to unit length
+			nrm := 0.0
+			for _, c := range z {
+				nrm = math.Hypot(nrm, cmplx.Abs(c))
+			}
+			for i := range z {
+				z[i] /= complex(nrm, 0)
+			}
+			f.vectors[j] = z
+		}
+	}
+
+	// Largest first, conjugate pairs have the same modulus so stay together
+	order := make([]int, nn)
+	for j := range order {
+		order[j] = j
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(cmplx.Abs(f.values[b]), cmplx.Abs(f.values[a]))
+	})
+	values := slices.Clone(f.values)
+	vecs := slices.Clone(f.vectors)
+	for k, j := range order {
+		f.values[k] = values[j]
+		if vectors {
+			f.vectors[k] = vecs[j]
+		}
+	}
+	return f, nil
+}
+
+// Reduce Hessenberg `h` to real Schur form in place by shifted QR iterations,
+// returning the real and imaginary parts of the eigenvalues. If `v` isn't nil
+// it holds the Hessenberg Q, and is replaced with the eigenvectors, a complex
+// pair having the real and imaginary parts in adjacent columns.
+func schur(h, v [][]float64) ([]float64, []float64, error) {
+	nn := len(h)
+	d := make([]float64, nn)
+	e := make([]float64, nn)
+	n := nn - 1
+	exshift := 0.0
+	var p, q, r, s, z, t, w, x, y float64
+
+	norm := 0.0
+	for i := 0; i < nn; i++ {
+		for j := max(i-1, 0); j < nn; j++ {
+			norm += math.Abs(h[i][j])
+		}
+	}
+
+	// Find eigenvalues from the bottom up
+	iter := 0
+	for n >= 0 {
+		// Look for a single small subdiagonal element
+		l := n
+		for l > 0 {
+			s = math.Abs(h[l-1][l-1]) + math.Abs(h[l][l])
+			if s == 0 {
+				s = norm
+			}
+			if math.Abs(h[l][l-1]) <= epsilon*s { // Not <, so a zero matrix deflates
+				break
+			}
+			l--
+		}
+
+		switch {
+		case l == n:
+			// One root found
+			h[n][n] += exshift
+			d[n], e[n] = h[n][n], 0
+			n--
+			iter = 0
+
+		case l == n-1:
+			// Two roots found
+			w = h[n][n-1] * h[n-1][n]
+			p = (h[n-1][n-1] - h[n][n]) / 2
+			q = p*p + w
+			z = math.Sqrt(math.Abs(q))
+			h[n][n] += exshift
+			h[n-1][n-1] += exshift
+			x = h[n][n]
+
+			if q >= 0 {
+				// A real pair
+				if p >= 0 {
+					z = p + z
+				} else {
+					z = p - z
+				}
+				d[n-1] = x + z
+				d[n] = d[n-1]
+				if z != 0 {
+					d[n] = x - w/z
+				}
+				e[n-1], e[n] = 0, 0
+				x = h[n][n-1]
+				s = math.Abs(x) + math.Abs(z)
+				p, q = 0, 1 // An all zero block is already triangular
+				if s != 0 {
+					p = x / s
+					q = z / s
+					r = math.Sqrt(p*p + q*q)
+					p /= r
+					q /= r
+				}
+
+				// Rotate the pair to upper triangular
+				for j := n - 1; j < nn; j++ {
+					z = h[n-1][j]
+					h[n-1][j] = q*z + p*h[n][j]
+					h[n][j] = q*h[n][j] - p*z
+				}
+				for i := 0; i <= n; i++ {
+					z = h[i][n-1]
+					h[i][n-1] = q*z + p*h[i][n]
+					h[i][n] = q*h[i][n] - p*z
+				}
+				for i := range v {
+					z = v[i][n-1]
+					v[i][n-1] = q*z + p*v[i][n]
+					v[i][n] = q*v[i][n] - p*z
+				}
+			} else {
+				// A complex conjugate pair
+				d[n-1], d[n] = x+p, x+p
+				e[n-1], e[n] = z, -z
+			}
+			n -= 2
+			iter = 0
+
+		default:
+			if iter >= maxQRIterations {
+				return nil, nil, fmt.Errorf("Eigenvalues did not converge in %d iterations", maxQRIterations)
+			}
+
+			// Form the shift
+			x = h[n][n]
+			y, w = 0, 0
+			if l < n {
+				y = h[n-1][n-1]
+				w = h[n][n-1] * h[n-1][n]
+			}
+
+			// Wilkinson's original ad hoc shift
+			if iter == 10 {
+				exshift += x
+				for i := 0; i <= n; i++ {
+					h[i][i] -= x
+				}
+				s = math.Abs(h[n][n-1]) + math.Abs(h[n-1][n-2])
+				x = 0.75 * s
+				y = x
+				w = -0.4375 * s * s
+			}
+
+			// MATLAB's ad hoc shift
+			if iter == 30 {
+				s = (y - x) / 2
+				s = s*s + w
+				if s > 0 {
+					s = math.Sqrt(s)
+					if y < x {
+						s = -s
+					}
+					s = x - w/((y-x)/2+s)
+					for i := 0; i <= n; i++ {
+						h[i][i] -= s
+					}
+					exshift += s
+					x, y, w = 0.964, 0.964, 0.964
+				}
+			}
+			iter++
+
+			// Look for two consecutive small subdiagonal elements
+			m := n - 2
+			for m >= l {
+				z = h[m][m]
+				r = x - z
+				s = y - z
+				p = (r*s-w)/h[m+1][m] + h[m][m+1]
+				q = h[m+1][m+1] - z - r - s
+				r = h[m+2][m+1]
+				s = math.Abs(p) + math.Abs(q) + math.Abs(r)
+				p /= s
+				q /= s
+				r /= s
+				if m == l {
+					break
+				}
+				if math.Abs(h[m][m-1])*(math.Abs(q)+math.Abs(r)) <
+					epsilon*(math.Abs(p)*(math.Abs(h[m-1][m-1])+math.Abs(z)+math.Abs(h[m+1][m+1]))) {
+					break
+				}
+				m--
+			}
+
+			for i := m + 2; i <= n; i++ {
+				h[i][i-2] = 0
+				if i > m+2 {
+					h[i][i-3] = 0
+				}
+			}
+
+			// Double QR step on rows l..n and columns m..n
+			for k := m; k <= n-1; k++ {
+				notlast := k != n-1
+				if k != m {
+					p = h[k][k-1]
+					q = h[k+1][k-1]
+					r = 0
+					if notlast {
+						r = h[k+2][k-1]
+					}
+					x = math.Abs(p) + math.Abs(q) + math.Abs(r)
+					if x == 0 {
+						continue
+					}
+					p /= x
+					q /= x
+					r /= x
+				}
+
+				s = math.Sqrt(p*p + q*q + r*r)
+				if p < 0 {
+					s = -s
+				}
+				if s == 0 {
+					continue
+				}
+				if k != m {
+					h[k][k-1] = -s * x
+				} else if l != m {
+					h[k][k-1] = -h[k][k-1]
+				}
+				p += s
+				x = p / s
+				y = q / s
+				z = r / s
+				q /= p
+				r /= p
+
+				for j := k; j < nn; j++ {
+					p = h[k][j] + q*h[k+1][j]
+					if notlast {
+						p += r * h[k+2][j]
+						h[k+2][j] -= p * z
+					}
+					h[k][j] -= p * x
+					h[k+1][j] -= p * y
+				}
+				for i := 0; i <= min(n, k+3); i++ {
+					p = x*h[i][k] + y*h[i][k+1]
+					if notlast {
+						p += z * h[i][k+2]
+						h[i][k+2] -= p * r
+					}
+					h[i][k] -= p
+					h[i][k+1] -= p * q
+				}
+				for i := range v {
+					p = x*v[i][k] + y*v[i][k+1]
+					if notlast {
+						p += z * v[i][k+2]
+						v[i][k+2] -= p * r
+					}
+					v[i][k] -= p
+					v[i][k+1] -= p * q
+				}
+			}
+		}
+	}
+
+	if v == nil || norm == 0 {
+		return d, e, nil
+	}
+
+	// Back substitute to find the eigenvectors of the Schur form
+	for n = nn - 1; n >= 0; n-- {
+		p = d[n]
+		q = e[n]
+
+		switch {
+		case q == 0:
+			// A real vector
+			l := n
+			h[n][n] = 1
+			for i := n - 1; i >= 0; i-- {
+				w = h[i][i] - p
+				r = 0
+				for j := l; j <= n; j++ {
+					r += h[i][j] * h[j][n]
+				}
+				if e[i] < 0 {
+					z = w
+					s = r
+					continue
+				}
+				l = i
+				if e[i] == 0 {
+					if w != 0 {
+						h[i][n] = -r / w
+					} else {
+						h[i][n] = -r / (epsilon * norm)
+					}
+				} else {
+					x = h[i][i+1]
+					y = h[i+1][i]
+					q = (d[i]-p)*(d[i]-p) + e[i]*e[i]
+					t = (x*s - z*r) / q
+					h[i][n] = t
+					if math.Abs(x) > math.Abs(z) {
+						h[i+1][n] = (-r - w*t) / x
+					} else {
+						h[i+1][n] = (-s - y*t) / z
+					}
+				}
+				// Overflow control
+				t = math.Abs(h[i][n])
+				if (epsilon*t)*t > 1 {
+					for j := i; j <= n; j++ {
+						h[j][n] /= t
+					}
+				}
+			}
+
+		case q < 0:
+			// A complex vector, the second of its pair
+			l := n - 1
+			// The last component is imaginary so the matrix is triangular
+			if math.Abs(h[n][n-1]) > math.Abs(h[n-1][n]) {
+				h[n-1][n-1] = q / h[n][n-1]
+				h[n-1][n] = -(h[n][n] - p) / h[n][n-1]
+			} else {
+				c := complex(0, -h[n-1][n]) / complex(h[n-1][n-1]-p, q)
+				h[n-1][n-1], h[n-1][n] = real(c), imag(c)
+			}
+			h[n][n-1] = 0
+			h[n][n] = 1
+			for i := n - 2; i >= 0; i-- {
+				var ra, sa float64
+				for j := l; j <= n; j++ {
+					ra += h[i][j] * h[j][n-1]
+					sa += h[i][j] * h[j][n]
+				}
+				w = h[i][i] - p
+
+				if e[i] < 0 {
+					z = w
+					r = ra
+					s = sa
+					continue
+				}
+				l = i
+				if e[i] == 0 {
+					c := complex(-ra, -sa) / complex(w, q)
+					h[i][n-1], h[i][n] = real(c), imag(c)
+				} else {
+					// Solve the complex equations
+					x = h[i][i+1]
+					y = h[i+1][i]
+					vr := (d[i]-p)*(d[i]-p) + e[i]*e[i] - q*q
+					vi := (d[i] - p) * 2 * q
+					if vr == 0 && vi == 0 {
+						vr = epsilon * norm * (math.Abs(w) + math.Abs(q) + math.Abs(x) + math.Abs(y) + math.Abs(z))
+					}
+					c := complex(x*r-z*ra+q*sa, x*s-z*sa-q*ra) / complex(vr, vi)
+					h[i][n-1], h[i][n] = real(c), imag(c)
+					if math.Abs(x) > math.Abs(z)+math.Abs(q) {
+						h[i+1][n-1] = (-ra - w*h[i][n-1] + q*h[i][n]) / x
+						h[i+1][n] = (-sa - w*h[i][n] - q*h[i][n-1]) / x
+					} else {
+						c := complex(-r-y*h[i][n-1], -s-y*h[i][n]) / complex(z, q)
+						h[i+1][n-1], h[i+1][n] = real(c), imag(c)
+					}
+				}
+				// Overflow control
+				t = max(math.Abs(h[i][n-1]), math.Abs(h[i][n]))
+				if (epsilon*t)*t > 1 {
+					for j := i; j <= n; j++ {
+						h[j][n-1] /= t
+						h[j][n] /= t
+					}
+				}
+			}
+		}
+	}
+
+	// Back transform to the eigenvectors of the original matrix
+	for j := nn - 1; j >= 0; j-- {
+		for i := 0; i < nn; i++ {
+			z = 0
+			for k := 0; k <= j; k++ {
+				z += v[i][k] * h[k][j]
+			}
+			v[i][j] = z
+		}
+	}
+	return d, e, nil
+}
+
+// Returns the eigenvalues, largest in modulus first
+func (f Eigen) Values() []complex128 {
+	return slices.Clone(f.values)
+}
+
+// Returns the unit length eigenvectors, in the same order as the eigenvalues,
+// or nil if they weren't asked for
+func (f Eigen) Vectors() [][]complex128 {
+	if f.vectors == nil {
+		return nil
+	}
+	z := make([][]complex128, len(f.vectors))
+	for j, v := range f.vectors {
+		z[j] = slices.Clone(v)
+	}
+	return z
+}
+
+// Returns the largest modulus of the eigenvalues. Powers of the matrix decay
+// to zero exactly when this is less than 1.
+func (f Eigen) SpectralRadius() float64 {
+	if len(f.values) == 0 {
+		return 0
+	}
+	return cmplx.Abs(f.values[0])
+}
@@ -0,0 +1,202 @@
+package matrix
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestHessenberg(t *testing.T) {
+	x := fromSliceOfSlices([][]float64{
+		{4, 1, -2, 2},
+		{1, 2, 0, 1},
+		{-2, 0, 3, -2},
+		{2, 1, -2, -1},
+	})
+	h, q, err := Hessenberg(x)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 2; i < 4; i++ {
+		for j := 0; j < i-1; j++ {
+			if h.At(i, j) != 0 {
+				t.Errorf("expected zero below the subdiagonal, got %v at %d, %d", h.At(i, j), i, j)
+			}
+		}
+	}
+	qh, _ := Multiply(q, h)
+	qhq, _ := Multiply(qh, Transpose(q))
+	if !near(qhq, x, 1e-12) {
+		t.Errorf("expected to be the same, got %v, want %v", qhq, x)
+	}
+	qtq, _ := Multiply(Transpose(q), q)
+	if !near(qtq, Identity(4), 1e-12) {
+		t.Errorf("expected Q to be orthogonal, got %v", qtq)
+	}
+}
+
+func TestDecomposeEigen(t *testing.T) {
+	type TestCase struct {
+		desc   string
+		input  Matrix
+		values []complex128
+	}
+
+	test_cases := []TestCase{
+		{
+			desc: "eigenvalues of a rotation",
+			input: fromSliceOfSlices([][]float64{
+				{0, -1},
+				{1, 0},
+			}),
+			values: []complex128{1i, -1i},
+		},
+		{
+			desc: "eigenvalues of a transition matrix",
+			input: fromSliceOfSlices([][]float64{
+				{0.9, 0.1},
+				{0.5, 0.5},
+			}),
+			values: []complex128{1, 0.4},
+		},
+		{
+			desc: "eigenvalues of a triangular matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 2, 3},
+				{0, -4, 5},
+				{0, 0, 2},
+			}),
+			values: []complex128{-4, 2, 1},
+		},
+		{
+			desc: "eigenvalues with a complex pair",
+			input: fromSliceOfSlices([][]float64{
+				{1, -2, 0},
+				{2, 1, 0},
+				{1, 1, 3},
+			}),
+			values: []complex128{3, 1 + 2i, 1 - 2i},
+		},
+		{
+			desc: "eigenvalues of a symmetric matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 2, 0},
+				{2, 1, 0},
+				{0, 0, -2},
+			}),
+			values: []complex128{3, -2, -1},
+		},
+		{
+			desc:   "eigenvalues of a zero matrix",
+			input:  NewDense(2, 2),
+			values: []complex128{0, 0},
+		},
+		{
+			desc:   "eigenvalues of a 3x3 zero matrix",
+			input:  NewDense(3, 3),
+			values: []complex128{0, 0, 0},
+		},
+		{
+			desc:   "eigenvalues of a 4x4 zero matrix",
+			input:  NewDense(4, 4),
+			values: []complex128{0, 0, 0, 0},
+		},
+		{
+			desc: "eigenvalues with a zero block",
+			input: fromSliceOfSlices([][]float64{
+				{2, 1, 0},
+				{0, 0, 0},
+				{0, 0, 0},
+			}),
+			values: []complex128{2, 0, 0},
+		},
+		{
+			desc: "eigenvalues of a general 5x5 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 2, 3, 4, 5},
+				{-1, 0, 2, 1, 3},
+				{2, 1, -3, 0, 1},
+				{0, 4, 1, 2, -2},
+				{3, -1, 0, 1, 1},
+			}),
+		},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			eig, err := DecomposeEigen(test_case.input, true)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			values := eig.Values()
+			if test_case.values != nil {
+				if len(values) != len(test_case.values) {
+					t.Fatalf("expected to be the same, got %v, want %v", values, test_case.values)
+				}
+				for k := range values {
+					if cmplx.Abs(values[k]-test_case.values[k]) > 1e-12 {
+						t.Errorf("expected to be the same, got %v, want %v", values, test_case.values)
+						break
+					}
+				}
+			}
+
+			// A v = λ v, for each eigenvector
+			n, _ := test_case.input.Dims()
+			for k, v := range eig.Vectors() {
+				length := 0.0
+				for _, vi := range v {
+					length += real(vi * cmplx.Conj(vi))
+				}
+				if !(math.Abs(length-1) <= 1e-12) { // Also catches NaN
+					t.Errorf("expected a unit eigenvector for λ = %v, got %v", values[k], v)
+				}
+				for i := 0; i < n; i++ {
+					var av complex128
+					for j := 0; j < n; j++ {
+						av += complex(test_case.input.At(i, j), 0) * v[j]
+					}
+					if cmplx.Abs(av-values[k]*v[i]) > 1e-10 {
+						t.Errorf("expected A v = λ v for λ = %v, got %v, want %v", values[k], av, values[k]*v[i])
+					}
+				}
+			}
+
+			// The eigenvalues alone agree
+			only, err := DecomposeEigen(test_case.input, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if only.Vectors() != nil {
+				t.Errorf("expected no eigenvectors, got %v", only.Vectors())
+			}
+			for k, v := range only.Values() {
+				if cmplx.Abs(v-values[k]) > 1e-12 {
+					t.Errorf("expected to be the same, got %v, want %v", only.Values(), values)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSpectralRadius(t *testing.T) {
+	// Powers of a substochastic matrix decay
+	eig, err := DecomposeEigen(fromSliceOfSlices([][]float64{
+		{0.5, 0.3},
+		{0.2, 0.6},
+	}), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if r := eig.SpectralRadius(); r >= 1 || r < 0.79 {
+		t.Errorf("expected to be the same, got %v, want 0.8", r)
+	}
+}
+
+func TestDecomposeEigenNotSquare(t *testing.T) {
+	_, err := DecomposeEigen(NewDense(2, 3), false)
+	if err == nil {
+		t.Errorf("expected an error for a non-square matrix")
+	}
+}
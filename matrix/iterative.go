@@ -0,0 +1,205 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+)
+
+// Settings for the iterative solvers, zero values take the defaults
+type IterativeOptions struct {
+	Tolerance     float64 // Relative residual at which to stop, DefaultTolerance if zero
+	MaxIterations int     // Most iterations before giving up, 10 times the number of unknowns if zero
+}
+
+// Relative residual at which the iterative solvers stop by default
+const DefaultTolerance = 1e-10
+
+// The outcome of an iterative solve. Not converging isn't an error, `X` is
+// then the best estimate found, so check `Converged`.
+type IterativeResult struct {
+	X          Vector  // The solution
+	Iterations int     // Iterations taken
+	Residual   float64 // ||b - AX||
+	Converged  bool    // Whether the tolerance was reached within the iteration limit
+}
+
+// Fill in the defaults for a problem with `m` unknowns
+func (o IterativeOptions) withDefaults(m int) IterativeOptions {
+	if o.Tolerance <= 0 {
+		o.Tolerance = DefaultTolerance
+	}
+	if o.MaxIterations <= 0 {
+		o.MaxIterations = 10 * max(m, 1)
+	}
+	return o
+}
+
+// Returns `b` as a vector, checking it has `n` rows
+func rightHandSide(b Matrix, n int) (Vector, error) {
+	bn, cols := b.Dims()
+	if bn != n || cols != 1 {
+		return nil, fmt.Errorf("Expected `b` to be %d x 1, got a %d x %d", n, bn, cols)
+	}
+	return ToVector(b)
+}
+
+// z = Ax, only touching the non-zeros of A
+func mulVec(a Matrix, x, z []float64) {
+	clear(z)
+	a.NonZeros(func(i, j int, v float64) {
+		z[i] += v * x[j]
+	})
+}
+
+// z = A'x, only touching the non-zeros of A
+func mulVecT(a Matrix, x, z []float64) {
+	clear(z)
+	a.NonZeros(func(i, j int, v float64) {
+		z[j] += v * x[i]
+	})
+}
+
+func dot(x, y []float64) float64 {
+	z := 0.0
+	for i := range x {
+		z += x[i] * y[i]
+	}
+	return z
+}
+
+// Solve AX = `b` for symmetric positive definite `a` by conjugate gradients.
+// Only products with `a` are used, so it suits large sparse matrices. It stops
+// when ||b - AX|| <= Tolerance ||b||.
+func ConjugateGradient(a, b Matrix, opts IterativeOptions) (IterativeResult, error) {
+	if ok, err := isSquare(a); !ok {
+		return IterativeResult{}, err
+	}
+	n, _ := a.Dims()
+	r, err := rightHandSide(b, n)
+	if err != nil {
+		return IterativeResult{}, err
+	}
+	opts = opts.withDefaults(n)
+
+	// Starting from X = 0 the residual is b
+	x := NewVector(n)
+	p := r.Copy()
+	ap := NewVector(n)
+	rr := dot(r, r)
+	target := opts.Tolerance * math.Sqrt(rr)
+
+	res := IterativeResult{Residual: math.Sqrt(rr)}
+	for res.Residual > target && res.Iterations < opts.MaxIterations {
+		mulVec(a, p, ap)
+		pap := dot(p, ap)
+		if !(pap > 0) {
+			return IterativeResult{}, fmt.Errorf("Matrix is not positive definite")
+		}
+		alpha := rr / pap
+		x.Axpy(alpha, p)
+		r.Axpy(-alpha, ap)
+		next := dot(r, r)
+		for i := range p {
+			p[i] = r[i] + next/rr*p[i]
+		}
+		rr = next
+		res.Iterations++
+		res.Residual = math.Sqrt(rr)
+	}
+
+	res.X = x
+	res.Converged = res.Residual <= target
+	return res, nil
+}
+
+// Returns the least squares solution X minimising ||AX - b|| by LSQR (Paige
+// and Saunders, 1982), which is conjugate gradients on the normal equations
+// without forming A'A. Only products with `a` and `a`' are used, so it suits
+// large sparse matrices. Starting from zero it converges to the minimum norm
+// solution when `a` is rank deficient. It stops when AX = b to within
+// Tolerance, or when ||A'(b - AX)|| <= Tolerance ||A|| ||b - AX||.
+func LSQR(a, b Matrix, opts IterativeOptions) (IterativeResult, error) {
+	n, m := a.Dims()
+	u, err := rightHandSide(b, n)
+	if err != nil {
+		return IterativeResult{}, err
+	}
+	opts = opts.withDefaults(m)
+
+	x := NewVector(m)
+	res := IterativeResult{X: x, Converged: true}
+
+	// Golub-Kahan bidiagonalisation, beta u = b and alpha v = A'u
+	beta := u.Norm(2)
+	if beta == 0 {
+		return res, nil // X = 0 exactly
+	}
+	scaleVec(u, 1/beta)
+	v := NewVector(m)
+	mulVecT(a, u, v)
+	alpha := v.Norm(2)
+	res.Residual = beta
+	if alpha == 0 {
+		return res, nil // b is orthogonal to the columns of A, so X = 0
+	}
+	scaleVec(v, 1/alpha)
+
+	w := v.Copy()
+	av := NewVector(n)
+	atu := NewVector(m)
+	phibar, rhobar := beta, alpha
+	bnorm, anorm := beta, 0.0
+
+	res.Converged = false
+	for res.Iterations < opts.MaxIterations {
+		res.Iterations++
+
+		// Continue the bidiagonalisation
+		mulVec(a, v, av)
+		for i := range u {
+			u[i] = av[i] - alpha*u[i]
+		}
+		beta = u.Norm(2)
+		if beta > 0 {
+			scaleVec(u, 1/beta)
+		}
+		anorm = math.Sqrt(anorm*anorm + alpha*alpha + beta*beta)
+		mulVecT(a, u, atu)
+		for j := range v {
+			v[j] = atu[j] - beta*v[j]
+		}
+		alpha = v.Norm(2)
+		if alpha > 0 {
+			scaleVec(v, 1/alpha)
+		}
+
+		// A plane rotation eliminates beta from the lower bidiagonal
+		rho := math.Hypot(rhobar, beta)
+		c, s := rhobar/rho, beta/rho
+		theta := s * alpha
+		rhobar = -c * alpha
+		phi := c * phibar
+		phibar = s * phibar
+
+		x.Axpy(phi/rho, w)
+		for j := range w {
+			w[j] = v[j] - theta/rho*w[j]
+		}
+
+		// phibar is ||b - AX|| and alpha |s phi| is ||A'(b - AX)||
+		res.Residual = phibar
+		arnorm := alpha * math.Abs(s*phi)
+		if phibar <= opts.Tolerance*bnorm || arnorm <= opts.Tolerance*anorm*phibar {
+			res.Converged = true
+			break
+		}
+	}
+
+	return res, nil
+}
+
+func scaleVec(x []float64, a float64) {
+	for i := range x {
+		x[i] *= a
+	}
+}
@@ -0,0 +1,165 @@
+package matrix
+
+import (
+	"testing"
+)
+
+func TestConjugateGradient(t *testing.T) {
+	x := benchDesign(50, 5)
+	xtx, _ := Multiply(Transpose(x), x)
+	b := fromSliceOfSlices([][]float64{{1}, {-2}, {0.5}, {3}, {1}})
+
+	type TestCase struct {
+		desc  string
+		input Matrix
+	}
+
+	// A sparse tridiagonal matrix, like a discretised Laplacian
+	n := 200
+	lap := NewSparse(n, n)
+	for i := 0; i < n; i++ {
+		lap.Set(i, i, 2)
+		if i > 0 {
+			lap.Set(i, i-1, -1)
+			lap.Set(i-1, i, -1)
+		}
+	}
+
+	test_cases := []TestCase{
+		{desc: "solve X'X", input: xtx},
+		{desc: "solve a diagonal system", input: NewDiagonal([]float64{1, 2, 3, 4, 5})},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			res, err := ConjugateGradient(test_case.input, b, IterativeOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !res.Converged {
+				t.Errorf("expected to converge, got residual %v after %d iterations", res.Residual, res.Iterations)
+			}
+			want, _ := Solve(test_case.input, b)
+			if !near(res.X, want, 1e-8) {
+				t.Errorf("expected to be the same, got %v, want %v", res.X, want)
+			}
+		})
+	}
+
+	t.Run("solve a large sparse system", func(t *testing.T) {
+		rhs := NewDense(n, 1)
+		for i := 0; i < n; i++ {
+			rhs.Set(i, 0, 1)
+		}
+		res, err := ConjugateGradient(ToCSR(lap), rhs, IterativeOptions{Tolerance: 1e-12})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !res.Converged {
+			t.Errorf("expected to converge, got residual %v after %d iterations", res.Residual, res.Iterations)
+		}
+		ax, _ := Multiply(lap, res.X)
+		if !near(ax, rhs, 1e-8) {
+			t.Errorf("expected AX = b, got %v", ax)
+		}
+	})
+
+	t.Run("report running out of iterations", func(t *testing.T) {
+		rhs := NewDense(n, 1)
+		rhs.Set(0, 0, 1)
+		res, err := ConjugateGradient(lap, rhs, IterativeOptions{MaxIterations: 3})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if res.Converged || res.Iterations != 3 {
+			t.Errorf("expected not to converge in 3 iterations, got %v after %d", res.Converged, res.Iterations)
+		}
+	})
+
+	t.Run("fail on indefinite matrices", func(t *testing.T) {
+		_, err := ConjugateGradient(NewDiagonal([]float64{1, -1}), fromSliceOfSlices([][]float64{{1}, {1}}), IterativeOptions{})
+		if err == nil {
+			t.Errorf("expected an error for an indefinite matrix")
+		}
+	})
+
+	t.Run("fail on mismatched dimensions", func(t *testing.T) {
+		_, err := ConjugateGradient(xtx, NewDense(4, 1), IterativeOptions{})
+		if err == nil {
+			t.Errorf("expected an error for mismatched dimensions")
+		}
+	})
+}
+
+func TestLSQR(t *testing.T) {
+	x := benchDesign(50, 5)
+	y := NewDense(50, 1)
+	for i := 0; i < 50; i++ {
+		y.Set(i, 0, float64(i%9)-3)
+	}
+
+	t.Run("agree with QR", func(t *testing.T) {
+		for _, input := range []Matrix{x, ToCSR(x), ToCSC(x)} {
+			res, err := LSQR(input, y, IterativeOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !res.Converged {
+				t.Errorf("expected to converge, got residual %v after %d iterations", res.Residual, res.Iterations)
+			}
+			want, _ := SolveLeastSquares(x, y)
+			if !near(res.X, want, 1e-8) {
+				t.Errorf("expected to be the same, got %v, want %v", res.X, want)
+			}
+		}
+	})
+
+	t.Run("find the minimum norm solution of a rank deficient system", func(t *testing.T) {
+		collinear := fromSliceOfSlices([][]float64{
+			{1, 1, 2},
+			{1, 2, 4},
+			{1, 3, 6},
+			{1, 4, 8},
+		})
+		b := fromSliceOfSlices([][]float64{{1}, {3}, {2}, {5}})
+		res, err := LSQR(collinear, b, IterativeOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		svd, _ := DecomposeSVD(collinear)
+		want, _ := svd.Solve(b)
+		if !near(res.X, want, 1e-8) {
+			t.Errorf("expected to be the same, got %v, want %v", res.X, want)
+		}
+	})
+
+	t.Run("solve a consistent system exactly", func(t *testing.T) {
+		a := fromSliceOfSlices([][]float64{{2, 1}, {1, 3}})
+		b := fromSliceOfSlices([][]float64{{3}, {4}})
+		res, err := LSQR(a, b, IterativeOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := fromSliceOfSlices([][]float64{{1}, {1}})
+		if !res.Converged || !near(res.X, want, 1e-10) {
+			t.Errorf("expected to be the same, got %v, want %v", res.X, want)
+		}
+	})
+
+	t.Run("a zero right hand side", func(t *testing.T) {
+		res, err := LSQR(x, NewDense(50, 1), IterativeOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !res.Converged || !near(res.X, NewDense(5, 1), 0) {
+			t.Errorf("expected zero, got %v", res.X)
+		}
+	})
+
+	t.Run("fail on mismatched dimensions", func(t *testing.T) {
+		_, err := LSQR(x, NewDense(49, 1), IterativeOptions{})
+		if err == nil {
+			t.Errorf("expected an error for mismatched dimensions")
+		}
+	})
+}
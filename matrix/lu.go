@@ -0,0 +1,194 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+/*
+The LU decomposition of a square `N` x `N` matrix with partial pivoting,
+`PA = LU` where P is a permutation, L is unit lower triangular and U is upper
+triangular.
+
+L is stored below the diagonal of `lu` (its diagonal of ones is implicit) and U
+on and above it. Row `i` of PA is row `pivot[i]` of A, and `sign` is the
+determinant of P.
+*/
+type LU struct {
+	lu    []float64 // Row major
+	pivot []int
+	sign  float64
+	N     int
+}
+
+// Decompose `x` into LU by Gaussian elimination, choosing the largest pivot in
+// each column
+func DecomposeLU(x Matrix) (LU, error) {
+	if b, err := isSquare(x); !b {
+		return LU{}, err
+	}
+	n, _ := x.Dims()
+
+	lu := toDense(x)
+	pivot := make([]int, n)
+	for i := range pivot {
+		pivot[i] = i
+	}
+	sign := 1.0
+
+	for k := 0; k < n; k++ {
+		// Find the pivot, the largest element on or below the diagonal
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i*n+k]) > math.Abs(lu[p*n+k]) {
+				p = i
+			}
+		}
+		if p != k {
+			for j := 0; j < n; j++ {
+				lu[p*n+j], lu[k*n+j] = lu[k*n+j], lu[p*n+j]
+			}
+			pivot[p], pivot[k] = pivot[k], pivot[p]
+			sign = -sign
+		}
+
+		if lu[k*n+k] == 0 {
+			continue // Singular, the column is already eliminated
+		}
+
+		// Eliminate below the pivot, keeping the multipliers as L
+		for i := k + 1; i < n; i++ {
+			l := lu[i*n+k] / lu[k*n+k]
+			lu[i*n+k] = l
+			if l == 0 {
+				continue
+			}
+			for j := k + 1; j < n; j++ {
+				lu[i*n+j] -= l * lu[k*n+j]
+			}
+		}
+	}
+
+	return LU{lu: lu, pivot: pivot, sign: sign, N: n}, nil
+}
+
+// Whether U, and so the matrix, has a zero on its diagonal
+func (f LU) IsSingular() bool {
+	for k := 0; k < f.N; k++ {
+		if f.lu[k*f.N+k] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the unit lower triangular factor L
+func (f LU) L() Matrix {
+	n := f.N
+	z := NewTriangular(n, false)
+	for i := 0; i < n; i++ {
+		copy(z.Values[i*n:i*n+i], f.lu[i*n:i*n+i])
+		z.Values[i*n+i] = 1
+	}
+	return z
+}
+
+// Returns the upper triangular factor U
+func (f LU) U() Matrix {
+	n := f.N
+	z := NewTriangular(n, true)
+	for i := 0; i < n; i++ {
+		copy(z.Values[i*n+i:(i+1)*n], f.lu[i*n+i:(i+1)*n])
+	}
+	return z
+}
+
+// Returns the permutation matrix P
+func (f LU) P() Matrix {
+	z := NewSparse(f.N, f.N)
+	for i, p := range f.pivot {
+		z.Set(i, p, 1)
+	}
+	return z
+}
+
+// Returns the row of the original matrix which each row of PA came from
+func (f LU) Pivot() []int {
+	return slices.Clone(f.pivot)
+}
+
+// Returns the determinant of P, +1 or -1 for an even or odd number of row swaps
+func (f LU) Sign() float64 {
+	return f.sign
+}
+
+// Returns the determinant, the signed product of the diagonal of U
+func (f LU) Det() float64 {
+	det := f.sign
+	for k := 0; k < f.N; k++ {
+		det *= f.lu[k*f.N+k]
+	}
+	return det
+}
+
+// Returns the log of the absolute value of the determinant, and its sign. This
+// won't overflow where Det would for large matrices.
+func (f LU) LogDet() (float64, float64) {
+	logDet, sign := 0.0, f.sign
+	for k := 0; k < f.N; k++ {
+		u := f.lu[k*f.N+k]
+		if u == 0 {
+			return math.Inf(-1), 0
+		}
+		if u < 0 {
+			sign = -sign
+		}
+		logDet += math.Log(math.Abs(u))
+	}
+	return logDet, sign
+}
+
+// Returns X solving AX = b, where PA = LU, by solving L Y = Pb then U X = Y
+func (f LU) Solve(b Matrix) (Matrix, error) {
+	bn, cols := b.Dims()
+	if bn != f.N {
+		return nil, fmt.Errorf("Expected `b` to have %d rows, got %d", f.N, bn)
+	}
+	if f.IsSingular() {
+		return nil, fmt.Errorf("Matrix is singular")
+	}
+
+	n := f.N
+	z := make([]float64, n*cols)
+	for i, p := range f.pivot {
+		for j := 0; j < cols; j++ {
+			z[i*cols+j] = b.At(p, j)
+		}
+	}
+
+	// Forward substitution with L
+	for k := 0; k < n; k++ {
+		for i := k + 1; i < n; i++ {
+			l := f.lu[i*n+k]
+			for j := 0; j < cols; j++ {
+				z[i*cols+j] -= l * z[k*cols+j]
+			}
+		}
+	}
+
+	// Back substitution with U
+	for k := n - 1; k >= 0; k-- {
+		for j := 0; j < cols; j++ {
+			z[k*cols+j] /= f.lu[k*n+k]
+		}
+		for i := 0; i < k; i++ {
+			u := f.lu[i*n+k]
+			for j := 0; j < cols; j++ {
+				z[i*cols+j] -= u * z[k*cols+j]
+			}
+		}
+	}
+
+	return fromDense(n, cols, z), nil
+}
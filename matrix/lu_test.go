@@ -0,0 +1,204 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecomposeLU(t *testing.T) {
+	type TestCase struct {
+		desc  string
+		input Matrix
+	}
+
+	test_cases := []TestCase{
+		{
+			desc: "LU of a 3x3 matrix needing pivots",
+			input: fromSliceOfSlices([][]float64{
+				{0, 2, 1},
+				{1, 1, 1},
+				{4, 2, 5},
+			}),
+		},
+		{
+			desc: "LU of a 5x5 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{2, -1, 0, 3, 1},
+				{1, 4, 2, 0, -2},
+				{0, 3, 5, 1, 1},
+				{4, 0, 1, 2, 3},
+				{-1, 2, 0, 1, 6},
+			}),
+		},
+		{
+			desc: "LU of a singular matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 2, 3},
+				{2, 4, 6},
+				{1, 0, 1},
+			}),
+		},
+	}
+
+	t.Run("fail on non-square matrices", func(t *testing.T) {
+		if _, err := DecomposeLU(Zero(3, 2)); err == nil {
+			t.Errorf("expected LU to fail")
+		}
+	})
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			lu, err := DecomposeLU(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			l, u := lu.L(), lu.U()
+
+			if b, _ := IsLowerTriangular(l); !b {
+				t.Errorf("expected L to be lower triangular, got %v", l)
+			}
+			if b, _ := IsUpperTriangular(u); !b {
+				t.Errorf("expected U to be upper triangular, got %v", u)
+			}
+
+			pa, _ := Multiply(lu.P(), test_case.input)
+			got, _ := Multiply(l, u)
+			if !near(got, pa, 1e-12) {
+				t.Errorf("expected LU = PA, got %v, want %v", got, pa)
+			}
+
+			if det, _ := Det(lu.P()); det != lu.Sign() {
+				t.Errorf("expected to be the same, got %v, want %v", lu.Sign(), det)
+			}
+		})
+	}
+}
+
+func TestLUDet(t *testing.T) {
+	type TestCase struct {
+		desc  string
+		input Matrix
+		want  float64
+	}
+
+	test_cases := []TestCase{
+		{
+			desc:  "determinant of a 1x1 matrix",
+			input: fromSliceOfSlices([][]float64{{-3}}),
+			want:  -3,
+		},
+		{
+			desc: "determinant of a 4x4 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 2, 3, 4},
+				{5, 6, 7, 8},
+				{2, 6, 4, 8},
+				{3, 1, 1, 2},
+			}),
+			want: 72,
+		},
+		{
+			desc: "determinant of a 5x5 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{2, -1, 0, 3, 1},
+				{1, 4, 2, 0, -2},
+				{0, 3, 5, 1, 1},
+				{4, 0, 1, 2, 3},
+				{-1, 2, 0, 1, 6},
+			}),
+			want: -1101,
+		},
+		{
+			desc: "determinant of a singular 4x4 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 2, 3, 4},
+				{2, 4, 6, 8},
+				{0, 1, 0, 1},
+				{3, 1, 1, 2},
+			}),
+			want: 0,
+		},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got, err := Det(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if math.Abs(got-test_case.want) > 1e-9 {
+				t.Errorf("expected to be the same, got %v, want %v", got, test_case.want)
+			}
+		})
+	}
+
+	t.Run("agrees with the 3x3 formula", func(t *testing.T) {
+		x := fromSliceOfSlices([][]float64{
+			{1, 2, 3},
+			{3, 2, 1},
+			{2, 1, 3},
+		})
+		lu, _ := DecomposeLU(x)
+		if got := lu.Det(); math.Abs(got+12) > 1e-12 {
+			t.Errorf("expected to be the same, got %v, want %v", got, -12)
+		}
+	})
+
+	t.Run("log determinant where the determinant overflows", func(t *testing.T) {
+		values := make([]float64, 100)
+		for i := range values {
+			values[i] = 1e10
+		}
+		values[0] = -1e10
+		x := NewDiagonal(values)
+
+		if det, _ := Det(x); !math.IsInf(det, -1) {
+			t.Errorf("expected the determinant to overflow, got %v", det)
+		}
+		logDet, sign, err := LogDet(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if want := 1000 * math.Log(10); math.Abs(logDet-want) > 1e-9 || sign != -1 {
+			t.Errorf("expected to be the same, got %v (sign %v), want %v (sign -1)", logDet, sign, want)
+		}
+	})
+}
+
+func TestLUSolve(t *testing.T) {
+	a := fromSliceOfSlices([][]float64{
+		{0, 2, 1},
+		{1, 1, 1},
+		{4, 2, 5},
+	})
+
+	t.Run("solve with several right hand sides", func(t *testing.T) {
+		lu, _ := DecomposeLU(a)
+		b := fromSliceOfSlices([][]float64{{1, 0}, {0, 1}, {0, 0}})
+		got, err := lu.Solve(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ax, _ := Multiply(a, got)
+		if !near(ax, b, 1e-12) {
+			t.Errorf("expected AX = b, got %v, want %v", ax, b)
+		}
+	})
+
+	t.Run("fail on singular matrices", func(t *testing.T) {
+		lu, _ := DecomposeLU(fromSliceOfSlices([][]float64{{1, 2}, {2, 4}}))
+		if !lu.IsSingular() {
+			t.Errorf("expected matrix to be singular")
+		}
+		if _, err := lu.Solve(Zero(2, 1)); err == nil {
+			t.Errorf("expected solve to fail")
+		}
+	})
+
+	t.Run("fail on the wrong number of rows", func(t *testing.T) {
+		lu, _ := DecomposeLU(a)
+		if _, err := lu.Solve(Zero(2, 1)); err == nil {
+			t.Errorf("expected solve to fail")
+		}
+	})
+}
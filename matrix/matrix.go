@@ -2,109 +2,128 @@ package matrix
 
 import (
 	"fmt"
-	"maps"
 	"math"
-	"reflect"
+	"slices"
 )
 
-/*
-A sparse Matrix contains a map of values, indexed by a 2-dim array representing
-the coordinates of the Matrix. Where `Values[{0,0}]` is the top leftmost
-element and `Values[{N,M}]` is the bottome rightmost element.
-*/
-type Matrix struct {
-	Values map[[2]int]float64
+// A Matrix is anything with dimensions whose elements can be got and set
+type Matrix interface {
 	// Number of Rows and Columns
-	N, M int
+	Dims() (int, int)
+	// Get the value in a matrix at a point
+	At(i, j int) float64
+	// Set a matrix value at a point
+	Set(i, j int, v float64) error
+	// Call `fn` for each non-zero element, in row major order
+	NonZeros(fn func(i, j int, v float64))
 }
 
-// Create a `n` x `m` matrix of zeros
+// Create a `n` x `m` matrix of zeros. Matrices built up element by element
+// tend to be filled in, so this is dense.
 func Zero(n, m int) Matrix {
-	values := make(map[[2]int]float64, n*m) // Allocate the maximum possible memory
-
-	return Matrix{
-		Values: values,
-		N:      n,
-		M:      m,
-	}
+	return NewDense(n, m)
 }
 
+// Above this size the identity is mostly zeros, so is stored sparsely
+const sparseIdentity = 64
+
 // Returns the `n` x `n` identity matrix
 func Identity(n int) Matrix {
-	z := Zero(n, n)
+	var z Matrix
+	if n > sparseIdentity {
+		z = NewSparse(n, n)
+	} else {
+		z = NewDense(n, n)
+	}
 	for i := 0; i < n; i++ {
 		z.Set(i, i, 1)
 	}
 	return z
 }
 
+// Returns a zero matrix with the same representation as `x`
+func zeroLike(x Matrix, n, m int) Matrix {
+	switch x := x.(type) {
+	case *Sparse:
+		return NewSparse(n, m)
+	case *Diagonal:
+		if n == m {
+			return NewDiagonal(make([]float64, n))
+		}
+	case *Triangular:
+		if n == m {
+			return NewTriangular(n, x.Upper)
+		}
+	}
+	return NewDense(n, m)
+}
+
 // Helper for consistent error messaging
-func (x *Matrix) isSquare() (bool, error) {
-	if x.N == x.M {
+func isSquare(x Matrix) (bool, error) {
+	n, m := x.Dims()
+	if n == m {
 		return true, nil
 	} else {
-		return false, fmt.Errorf("Expected a square matrix, got a %d x %d", x.N, x.M)
+		return false, fmt.Errorf("Expected a square matrix, got a %d x %d", n, m)
 	}
 }
 
-// Get the value in a matrix at a point
-func (x *Matrix) Get(i, j int) float64 {
-	return x.Values[[2]int{i, j}]
-}
-
-// Set a matrix value at a point
-func (x *Matrix) Set(i, j int, v float64) error {
-	if i > x.N || j > x.M || i < 0 || j < 0 {
-		return fmt.Errorf("Invalid address")
-	}
-	if v == 0 {
-		delete(x.Values, [2]int{i, j})
+// Copy a matrix in it's entirety
+func Copy(x Matrix) Matrix {
+	switch x := x.(type) {
+	case *Dense:
+		return x.Copy()
+	case *Sparse:
+		return x.Copy()
+	case *Diagonal:
+		return x.Copy()
+	case *Triangular:
+		return x.Copy()
+	case *CSR:
+		return x.Copy()
+	case *CSC:
+		return x.Copy()
+	case Vector:
+		return x.Copy()
 	}
-	x.Values[[2]int{i, j}] = v
-	return nil
+	n, m := x.Dims()
+	return fromDense(n, m, toDense(x))
 }
 
-// Add to a value at a point
-func (x *Matrix) Update(i, j int, v float64) error {
-	if i > x.N || j > x.M || i < 0 || j < 0 {
-		return fmt.Errorf("Invalid address")
-	}
-	if v == 0 {
-		return nil // Don't want this to make new empty elements
+// Copy a matrix into a row major slice, for algorithms which need dense access
+func toDense(x Matrix) []float64 {
+	n, m := x.Dims()
+	switch x := x.(type) {
+	case *Dense:
+		return slices.Clone(x.Values)
+	case *Triangular:
+		return slices.Clone(x.Values)
 	}
-	x.Values[[2]int{i, j}] += v
-	return nil
+	z := make([]float64, n*m)
+	x.NonZeros(func(i, j int, v float64) {
+		z[i*m+j] = v
+	})
+	return z
 }
 
-// Copy a matrix in it's entirety
-func (x *Matrix) Copy() Matrix {
-	return Matrix{
-		Values: maps.Clone(x.Values), // OK to use as we are a shallow map
-		N:      x.N,
-		M:      x.M,
-	}
+// Create a `n` x `m` matrix from a row major slice
+func fromDense(n, m int, values []float64) Matrix {
+	return &Dense{Values: values, N: n, M: m}
 }
 
 // Check is two matrices are equal
 func Equal(x, y Matrix) bool {
-	if !(x.N == y.N && x.M == y.M) {
-		return false
-	}
-
-	// number of non-zero elements
-	if len(x.Values) != len(y.Values) {
+	xn, xm := x.Dims()
+	yn, ym := y.Dims()
+	if !(xn == yn && xm == ym) {
 		return false
 	}
 
-	for xk, xv := range x.Values {
-		yv, ok := y.Values[xk]
-		if !ok { // Does y have the same elements as x?
-			return false
-		}
-
-		if diff := math.Abs(xv - yv); diff > Fuzz {
-			fmt.Println(diff)
-			return false
+	for i := 0; i < xn; i++ {
+		for j := 0; j < xm; j++ {
+			if diff := math.Abs(x.At(i, j) - y.At(i, j)); diff > Fuzz {
+				return false
+			}
 		}
 	}
 	return true
@@ -113,144 +132,333 @@ func Equal(x, y Matrix) bool {
 // This value is used for comparisons to check for fuzz
 var Fuzz = 1.0e-14
 
-func (x *Matrix) fuzzCheck() {
-	for k, v := range x.Values {
-		if v == 0 || math.Abs(v) < Fuzz {
-			delete(x.Values, k)
+// Make any 'almost zeros' zero
+func fuzzCheck(x Matrix) {
+	switch x := x.(type) {
+	case *Dense:
+		for i, v := range x.Values {
+			if math.Abs(v) < Fuzz {
+				x.Values[i] = 0
+			}
+		}
+	case *Sparse:
+		for k, v := range x.Values {
+			if v == 0 || math.Abs(v) < Fuzz {
+				delete(x.Values, k)
+			}
+		}
+	case *Diagonal:
+		for i, v := range x.Values {
+			if math.Abs(v) < Fuzz {
+				x.Values[i] = 0
+			}
+		}
+	case *Triangular:
+		for i, v := range x.Values {
+			if math.Abs(v) < Fuzz {
+				x.Values[i] = 0
+			}
+		}
+	case Vector:
+		for i, v := range x {
+			if math.Abs(v) < Fuzz {
+				x[i] = 0
+			}
 		}
+	case *CSR:
+		x.ColIdx, x.Values = prune(x.RowPtr, x.ColIdx, x.Values, Fuzz)
+	case *CSC:
+		x.RowIdx, x.Values = prune(x.ColPtr, x.RowIdx, x.Values, Fuzz)
 	}
 }
 
-// Returns the transpose of matrix `x`
+// Returns the transpose of matrix `x`, in the same representation where there
+// is one
 func Transpose(x Matrix) Matrix {
-	// Empty matrix
-	if reflect.DeepEqual(x, Matrix{}) {
-		return x
-	}
-
-	z := Zero(x.M, x.N)
-
-	for k, v := range x.Values {
-		z.Set(k[1], k[0], v)
+	switch x := x.(type) {
+	case nil:
+		return nil
+	case *Sparse:
+		z := NewSparse(x.M, x.N)
+		for k, v := range x.Values {
+			z.Values[[2]int{k[1], k[0]}] = v
+		}
+		return z
+	case *Dense:
+		z := NewDense(x.M, x.N)
+		for i := 0; i < x.N; i++ {
+			for j, v := range x.Row(i) {
+				z.Values[j*x.N+i] = v
+			}
+		}
+		return z
+	case *Diagonal:
+		return x.Copy()
+	case *Triangular:
+		z := NewTriangular(x.N, !x.Upper)
+		for i := 0; i < x.N; i++ {
+			for j := 0; j < x.N; j++ {
+				z.Values[j*x.N+i] = x.Values[i*x.N+j]
+			}
+		}
+		return z
+	case *CSR:
+		ptr, idx, values := transposeCompressed(x.N, x.M, x.RowPtr, x.ColIdx, x.Values)
+		return &CSR{RowPtr: ptr, ColIdx: idx, Values: values, N: x.M, M: x.N}
+	case *CSC:
+		ptr, idx, values := transposeCompressed(x.M, x.N, x.ColPtr, x.RowIdx, x.Values)
+		return &CSC{ColPtr: ptr, RowIdx: idx, Values: values, N: x.M, M: x.N}
 	}
 
+	n, m := x.Dims()
+	z := NewDense(m, n)
+	x.NonZeros(func(i, j int, v float64) {
+		z.Values[j*n+i] = v
+	})
 	return z
 }
 
-// Performs matrix multiplication between matrices `x` and `y`
+// Below this proportion of non-zeros a product of sparse matrices stays sparse
+const sparseDensity = 0.1
+
+// Performs matrix multiplication between matrices `x` and `y`. Two dense
+// matrices multiply directly on their storage, two sparse enough matrices only
+// visit their non-zeros and give a sparse result, and anything else visits the
+// non-zeros of `x` to give a dense result. Compressed matrices keep their format
+// when multiplied together, and give a dense result with a dense matrix. Large
+// dense and compressed products are shared between Workers() goroutines.
 func Multiply(x Matrix, y Matrix) (Matrix, error) {
-	if x.M != y.N {
-		return Matrix{}, fmt.Errorf("Expected the number of `x` columns to be the same as the number of `y` rows")
+	xn, xm := x.Dims()
+	yn, ym := y.Dims()
+	if xm != yn {
+		return nil, fmt.Errorf("Expected the number of `x` columns to be the same as the number of `y` rows")
 	}
 
-	z := Zero(x.N, y.M)
-	for i := 0; i < x.N; i++ {
-		for j := 0; j < y.M; j++ {
-			for k := 0; k < x.M; k++ {
-				z.Update(i, j, x.Get(i, k)*y.Get(k, j))
+	xd, xDense := x.(*Dense)
+	yd, yDense := y.(*Dense)
+	xs, xSparse := x.(*Sparse)
+	ys, ySparse := y.(*Sparse)
+	xr, xCSR := x.(*CSR)
+	yr, yCSR := y.(*CSR)
+	xc, xCSC := x.(*CSC)
+	yc, yCSC := y.(*CSC)
+
+	var z Matrix
+	switch {
+	case xDense && yDense:
+		z = multiplyDense(xd, yd)
+	case xSparse && ySparse && xs.Density() < sparseDensity && ys.Density() < sparseDensity:
+		z = multiplySparse(xs, ys)
+	case xCSR && yCSR:
+		z = multiplyCSR(xr, yr)
+	case xCSC && yCSC:
+		z = multiplyCSC(xc, yc)
+	case xCSR && yCSC:
+		z = multiplyCSR(xr, ToCSR(yc))
+	case xCSR && yDense:
+		z = multiplyCSRDense(xr, yd)
+	case xCSC && yDense:
+		z = multiplyCSRDense(ToCSR(xc), yd)
+	case xDense && yCSC:
+		z = multiplyDenseCSC(xd, yc)
+	default:
+		zd := NewDense(xn, ym)
+		x.NonZeros(func(i, k int, a float64) {
+			row := zd.Row(i)
+			if yd != nil {
+				for j, b := range yd.Row(k) {
+					row[j] += a * b
+				}
+				return
 			}
-		}
+			if yr != nil {
+				for p := yr.RowPtr[k]; p < yr.RowPtr[k+1]; p++ {
+					row[yr.ColIdx[p]] += a * yr.Values[p]
+				}
+				return
+			}
+			for j := range row {
+				row[j] += a * y.At(k, j)
+			}
+		})
+		z = zd
 	}
 
-	z.fuzzCheck()
+	fuzzCheck(z)
 
 	return z, nil
+}
+
+// Sparse product, only pairs of non-zeros contribute
+func multiplySparse(x, y *Sparse) *Sparse {
+	// Index the non-zeros of y by row
+	rows := make(map[int][][2]float64)
+	for k, v := range y.Values {
+		rows[k[0]] = append(rows[k[0]], [2]float64{float64(k[1]), v})
+	}
 
+	z := NewSparse(x.N, y.M)
+	for k, a := range x.Values {
+		for _, jb := range rows[k[1]] {
+			z.Update(k[0], int(jb[0]), a*jb[1])
+		}
+	}
+	return z
 }
 
 // Returns matrices `x` and `y` added together
 func Add(x, y Matrix) (Matrix, error) {
-	if x.N != y.N && x.M != y.M {
-		return Matrix{}, fmt.Errorf("`x` and `y` must be of the same dimension")
+	xn, xm := x.Dims()
+	yn, ym := y.Dims()
+	if xn != yn || xm != ym {
+		return nil, fmt.Errorf("`x` and `y` must be of the same dimension")
 	}
 
-	z := Zero(x.N, x.M)
+	xs, xSparse := x.(*Sparse)
+	ys, ySparse := y.(*Sparse)
+	if xSparse && ySparse {
+		z := xs.Copy()
+		for k, v := range ys.Values {
+			z.Update(k[0], k[1], v)
+		}
+		fuzzCheck(z) // Cancelled out elements
+		return z, nil
+	}
 
-	for i := 0; i < x.N; i++ {
-		for j := 0; j < x.M; j++ {
-			z.Set(i, j, x.Get(i, j)+y.Get(i, j))
+	_, xCSR := x.(*CSR)
+	_, yCSR := y.(*CSR)
+	_, xCSC := x.(*CSC)
+	_, yCSC := y.(*CSC)
+	if (xCSR || xCSC) && (yCSR || yCSC) {
+		triplets := append(nonZeroTriplets(x), nonZeroTriplets(y)...)
+		var z Matrix
+		if xCSC && yCSC {
+			z, _ = NewCSC(xn, xm, triplets)
+		} else {
+			z, _ = NewCSR(xn, xm, triplets)
 		}
+		fuzzCheck(z) // Cancelled out elements
+		return z, nil
 	}
 
+	z := NewDense(xn, xm)
+	x.NonZeros(func(i, j int, v float64) {
+		z.Values[i*xm+j] += v
+	})
+	y.NonZeros(func(i, j int, v float64) {
+		z.Values[i*xm+j] += v
+	})
+
 	return z, nil
 }
 
-// Returns matrix `x` scaled by factor `a`
+// Returns matrix `x` scaled by factor `a`, in the same representation
 func Scale(x Matrix, a float64) Matrix {
-	z := Zero(x.N, x.M)
-
-	for i := 0; i < x.N; i++ {
-		for j := 0; j < x.M; j++ {
-			z.Set(i, j, x.Get(i, j)*a)
+	switch x := x.(type) {
+	case *CSR:
+		z := x.Copy()
+		for p := range z.Values {
+			z.Values[p] *= a
+		}
+		z.ColIdx, z.Values = prune(z.RowPtr, z.ColIdx, z.Values, 0)
+		return z
+	case *CSC:
+		z := x.Copy()
+		for p := range z.Values {
+			z.Values[p] *= a
 		}
+		z.RowIdx, z.Values = prune(z.ColPtr, z.RowIdx, z.Values, 0)
+		return z
 	}
 
+	n, m := x.Dims()
+	z := zeroLike(x, n, m)
+
+	x.NonZeros(func(i, j int, v float64) {
+		z.Set(i, j, v*a)
+	})
+
 	return z
 }
 
 // returns the determinant of matrix `x`
 func Det(x Matrix) (float64, error) {
-	if b, err := x.isSquare(); !b {
+	if b, err := isSquare(x); !b {
 		return 0.0, err
 	}
+	n, _ := x.Dims()
 
 	// some simple cases we can account for easily
-	switch x.N {
+	switch n {
 	case 2:
-		return x.Get(0, 0)*x.Get(1, 1) - x.Get(1, 0)*x.Get(1, 1), nil
+		return x.At(0, 0)*x.At(1, 1) - x.At(0, 1)*x.At(1, 0), nil
 	case 3:
-		aei := x.Get(0, 0) * x.Get(1, 1) * x.Get(2, 2)
-		bfg := x.Get(0, 1) * x.Get(1, 2) * x.Get(2, 0)
-		cdh := x.Get(0, 2) * x.Get(1, 0) * x.Get(2, 1)
+		aei := x.At(0, 0) * x.At(1, 1) * x.At(2, 2)
+		bfg := x.At(0, 1) * x.At(1, 2) * x.At(2, 0)
+		cdh := x.At(0, 2) * x.At(1, 0) * x.At(2, 1)
 
-		ceg := x.Get(0, 2) * x.Get(1, 1) * x.Get(2, 0)
-		bdi := x.Get(0, 1) * x.Get(1, 0) * x.Get(2, 2)
-		afh := x.Get(0, 0) * x.Get(1, 2) * x.Get(2, 1)
+		ceg := x.At(0, 2) * x.At(1, 1) * x.At(2, 0)
+		bdi := x.At(0, 1) * x.At(1, 0) * x.At(2, 2)
+		afh := x.At(0, 0) * x.At(1, 2) * x.At(2, 1)
 
 		return aei + bfg + cdh - ceg - bdi - afh, nil
 	}
 
-	// We are going to try to implement this in the following way:
-	// LU decomposition, this is probably worthy of a new file at this point
+	lu, err := DecomposeLU(x)
+	if err != nil {
+		return 0, err
+	}
+	return lu.Det(), nil
+}
 
-	return 1, nil
+// Returns the log of the absolute value of the determinant of matrix `x`, and
+// its sign
+func LogDet(x Matrix) (float64, float64, error) {
+	lu, err := DecomposeLU(x)
+	if err != nil {
+		return 0, 0, err
+	}
+	logDet, sign := lu.LogDet()
+	return logDet, sign, nil
 }
 
 // Returns the inverse of matrix `x`
 func Inverse(x Matrix) (Matrix, error) {
-	if b, err := x.isSquare(); !b {
-		return Matrix{}, err
+	if b, err := isSquare(x); !b {
+		return nil, err
 	}
+	n, _ := x.Dims()
 
 	// some simple cases we can account
-	switch x.M {
+	switch n {
 	case 1:
-		z := Zero(x.N, x.M)
-		if v := x.Get(0, 0); v != 0 {
-			z.Set(0, 0, 1/x.Get(0, 0))
+		z := Zero(n, n)
+		if v := x.At(0, 0); v != 0 {
+			z.Set(0, 0, 1/x.At(0, 0))
 			return z, nil
 		} else {
-			return Matrix{}, fmt.Errorf("No inverse")
+			return nil, fmt.Errorf("No inverse")
 		}
 	case 2:
 		det, err := Det(x)
 		if err != nil {
-			return Matrix{}, err
+			return nil, err
 		}
 		if det == 0 {
-			return Matrix{}, fmt.Errorf("No inverse (determinant = 0)")
+			return nil, fmt.Errorf("No inverse (determinant = 0)")
 		}
-		z := Zero(x.N, x.M)
-		z.Set(0, 0, x.Get(1, 1))
-		z.Set(0, 1, -x.Get(0, 1))
-		z.Set(1, 0, -x.Get(1, 0))
-		z.Set(1, 1, x.Get(0, 0))
-		return Scale(z, 1/math.Abs(det)), nil
+		z := Zero(n, n)
+		z.Set(0, 0, x.At(1, 1))
+		z.Set(0, 1, -x.At(0, 1))
+		z.Set(1, 0, -x.At(1, 0))
+		z.Set(1, 1, x.At(0, 0))
+		return Scale(z, 1/det), nil
 	}
 
 	// This ends the simple cases I can be bothered to do (3x3 and 4x4 are feasible too)
-	z := x.Copy()      // Copy X so as to we can keep its original values/properties etc...
-	p := Identity(x.N) // Will become our inverse
+	z := fromDense(n, n, toDense(x)) // Copy X densely so the row operations are in place and O(n)
+	p := denseIdentity(n)            // Will become our inverse
+	id := Identity(n)
 
 	// Current Row
 	i := 0
@@ -258,36 +466,33 @@ func Inverse(x Matrix) (Matrix, error) {
 	j := 0
 
 	for {
-		z.fuzzCheck() // Make any 'almost zeros' zero/ensure sparsity
+		fuzzCheck(z) // Make any 'almost zeros' zero/ensure sparsity
 
 		// Check to see if we actually need to do anything
-		if id := Identity(x.N); Equal(id, z) {
+		if Equal(id, z) {
 			return p, nil
 		}
 		// If we are past x.M/x.N
-		if i >= x.N {
+		if i >= n {
 			return p, fmt.Errorf("Overflowed rows => not invertible, j = %d", j)
 		}
-		if j >= x.M {
-			return Matrix{}, fmt.Errorf("Overflowed columns => not invertible, i = %d", i)
+		if j >= n {
+			return nil, fmt.Errorf("Overflowed columns => not invertible, i = %d", i)
 		}
 
 		// find pivot
-		if z.Get(i, j) == 0 {
-			for k := i; k <= x.N; k++ {
-				if k == x.N {
+		if z.At(i, j) == 0 {
+			for k := i; k <= n; k++ {
+				if k == n {
 					j += 1 // Overflowed rows, meaning all zero, move to next column
 					break  // Kick us to the continue
 				}
-				if z.Get(k, j) != 0 {
-					var err error
-					z, err = SwapRows(z, i, k)
-					if err != nil {
-						return Matrix{}, err
+				if z.At(k, j) != 0 {
+					if err := SwapRowsInPlace(z, i, k); err != nil {
+						return nil, err
 					}
-					p, err = SwapRows(p, i, k) // We need to track permutations too
-					if err != nil {
-						return Matrix{}, err
+					if err := SwapRowsInPlace(p, i, k); err != nil { // We need to track permutations too
+						return nil, err
 					}
 					break
 				}
@@ -296,27 +501,25 @@ func Inverse(x Matrix) (Matrix, error) {
 		}
 
 		// Make all other column entries zero
-		for k := 0; k < x.N; k++ {
-			var err error
+		for k := 0; k < n; k++ {
 			if k == i {
-				scale := z.Get(i, j)
-				z, err = ScaleRow(z, i, 1/scale) // Convert row so pivot is 1
-				if err != nil {
-					return Matrix{}, nil
+				scale := z.At(i, j)
+				if err := ScaleRowInPlace(z, i, 1/scale); err != nil { // Convert row so pivot is 1
+					return nil, err
 				}
-				p, err = ScaleRow(p, i, 1/scale)
-				if err != nil {
-					return Matrix{}, nil
+				if err := ScaleRowInPlace(p, i, 1/scale); err != nil {
+					return nil, err
 				}
 			} else {
-				scale := -(z.Get(k, j) / z.Get(i, j))
-				z, err = AddToRow(z, k, i, scale) // Add row scaled row i to row k
-				if err != nil {
-					return Matrix{}, err
+				scale := -(z.At(k, j) / z.At(i, j))
+				if scale == 0 {
+					continue
+				}
+				if err := AddToRowInPlace(z, k, i, scale); err != nil { // Add row scaled row i to row k
+					return nil, err
 				}
-				p, err = AddToRow(p, k, i, scale) // Add row scaled row i to row k
-				if err != nil {
-					return Matrix{}, err
+				if err := AddToRowInPlace(p, k, i, scale); err != nil {
+					return nil, err
 				}
 			}
 		}
@@ -18,17 +18,17 @@ func fromSliceOfSlices(x [][]float64) Matrix {
 		}
 	}
 
-	z.fuzzCheck() // Remove 0 values for good equals
+	fuzzCheck(z) // Remove 0 values for good equals
 	return z
 }
 
 func TestUpdate(t *testing.T) {
 	t.Run("what if we update an empty element?", func(t *testing.T) {
-		z := Zero(4, 4)
+		z := NewSparse(4, 4)
 		z.Update(1, 1, 3) // Does this element become 3?
 		z.Update(0, 0, 0) // Does this stay an empty element?
 
-		if z.Get(1, 1) != 3 {
+		if z.At(1, 1) != 3 {
 			t.Errorf("does not work on empties")
 		}
 		if _, got := z.Values[[2]int{0, 0}]; got {
@@ -273,6 +273,14 @@ func TestDet(t *testing.T) {
 			}),
 			want: -4,
 		},
+		{
+			desc: "determinant of a non-symmetric 2x2 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{4, 7},
+				{2, 6},
+			}),
+			want: 10,
+		},
 		{
 			desc: "determinant of a 3x3 matrix",
 			input: fromSliceOfSlices([][]float64{
@@ -337,8 +345,8 @@ func TestInverse(t *testing.T) {
 				{3, 2},
 			}),
 			want: fromSliceOfSlices([][]float64{
-				{0.5, -0.5},
-				{-0.75, 0.25},
+				{-0.5, 0.5},
+				{0.75, -0.25},
 			}),
 		},
 		{
@@ -446,3 +454,19 @@ func TestScale(t *testing.T) {
 		}
 	})
 }
+
+func TestInverseLarge(t *testing.T) {
+	// Diagonally dominant, so well conditioned
+	x := benchDesign(200, 200)
+	for i := 0; i < 200; i++ {
+		x.Set(i, i, x.At(i, i)+50)
+	}
+	inv, err := Inverse(x)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, _ := Multiply(x, inv)
+	if !near(got, Identity(200), 1e-10) {
+		t.Errorf("expected the product with the inverse to be the identity")
+	}
+}
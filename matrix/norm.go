@@ -0,0 +1,164 @@
+package matrix
+
+import (
+	"math"
+)
+
+// Returns the 1-norm of matrix `x`, the largest absolute column sum
+func Norm1(x Matrix) float64 {
+	_, m := x.Dims()
+	sums := make([]float64, m)
+	x.NonZeros(func(i, j int, v float64) {
+		sums[j] += math.Abs(v)
+	})
+	return Vector(sums).Norm(math.Inf(1))
+}
+
+// Returns the infinity norm of matrix `x`, the largest absolute row sum
+func NormInf(x Matrix) float64 {
+	n, _ := x.Dims()
+	sums := make([]float64, n)
+	x.NonZeros(func(i, j int, v float64) {
+		sums[i] += math.Abs(v)
+	})
+	return Vector(sums).Norm(math.Inf(1))
+}
+
+// Returns the Frobenius norm of matrix `x`, the square root of the sum of
+// its squared elements
+func NormFrobenius(x Matrix) float64 {
+	z := 0.0
+	x.NonZeros(func(i, j int, v float64) {
+		z = math.Hypot(z, v)
+	})
+	return z
+}
+
+// Returns the spectral norm (2-norm) of matrix `x`, its largest singular value
+func Norm2(x Matrix) (float64, error) {
+	svd, err := DecomposeSVD(x)
+	if err != nil {
+		return 0, err
+	}
+	if len(svd.s) == 0 {
+		return 0, nil
+	}
+	return svd.s[0], nil
+}
+
+// Returns an estimate of the 1-norm condition number ||x|| ||x^-1|| of square
+// matrix `x`, infinite if it is singular. Rather than inverting `x` the norm of
+// the inverse is estimated from a few solves with its LU decomposition, so
+// this is much cheaper than Cond. The estimate is never too large, and rarely
+// more than a factor of 3 too small.
+func CondEstimate(x Matrix) (float64, error) {
+	lu, err := DecomposeLU(x)
+	if err != nil {
+		return 0, err
+	}
+	if lu.N == 0 {
+		return 0, nil
+	}
+	if lu.IsSingular() {
+		return math.Inf(1), nil
+	}
+	return Norm1(x) * lu.inverseNorm1(), nil
+}
+
+// Most steps of the inverse norm estimate, it usually stops after 2 or 3
+const maxNormSteps = 5
+
+// Estimate ||A^-1|| in the 1-norm by Hager's method as refined by Higham
+// (1988), which LAPACK's condition estimates use. It climbs towards the column
+// of A^-1 with the largest norm, using solves with A and A'.
+func (f LU) inverseNorm1() float64 {
+	n := f.N
+	x := NewVector(n)
+	for i := range x {
+		x[i] = 1 / float64(n)
+	}
+	y := f.solveVector(x, false)
+	est := y.Norm(1)
+
+	last := -1 // x is the unit vector e_last after the first step
+	for k := 0; k < maxNormSteps; k++ {
+		for i, v := range y {
+			x[i] = 1
+			if v < 0 {
+				x[i] = -1
+			}
+		}
+		z := f.solveVector(x, true)
+		j := 0
+		for i := range z {
+			if math.Abs(z[i]) > math.Abs(z[j]) {
+				j = i
+			}
+		}
+		if last >= 0 && (j == last || math.Abs(z[j]) <= z[last]) {
+			break // At a local maximum
+		}
+
+		clear(x)
+		x[j] = 1
+		last = j
+		y = f.solveVector(x, false)
+		next := y.Norm(1)
+		if next <= est {
+			break
+		}
+		est = next
+	}
+
+	// Higham's alternating vector catches matrices the climb gets stuck on
+	for i := range x {
+		x[i] = 1 + float64(i)/float64(max(n-1, 1))
+		if i%2 == 1 {
+			x[i] = -x[i]
+		}
+	}
+	alt := 2 * f.solveVector(x, false).Norm(1) / float64(3*n)
+	return max(est, alt)
+}
+
+// Returns z solving Az = `b`, or A'z = `b` if `transpose`. As PA = LU, A'
+// is U'L'P, so the transposed solve is forwards with U', backwards with L',
+// and then undoes the permutation.
+func (f LU) solveVector(b Vector, transpose bool) Vector {
+	n := f.N
+	z := NewVector(n)
+	if !transpose {
+		for i, p := range f.pivot {
+			z[i] = b[p]
+		}
+		for i := 0; i < n; i++ {
+			for k := 0; k < i; k++ {
+				z[i] -= f.lu[i*n+k] * z[k]
+			}
+		}
+		for i := n - 1; i >= 0; i-- {
+			for k := i + 1; k < n; k++ {
+				z[i] -= f.lu[i*n+k] * z[k]
+			}
+			z[i] /= f.lu[i*n+i]
+		}
+		return z
+	}
+
+	w := b.Copy()
+	for i := 0; i < n; i++ {
+		for k := 0; k < i; k++ {
+			w[i] -= f.lu[k*n+i] * w[k]
+		}
+		w[i] /= f.lu[i*n+i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		for k := i + 1; k < n; k++ {
+			w[i] -= f.lu[k*n+i] * w[k]
+		}
+	}
+	for i, p := range f.pivot {
+		z[p] = w[i]
+	}
+	return z
+}
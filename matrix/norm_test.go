@@ -0,0 +1,123 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNorms(t *testing.T) {
+	x := fromSliceOfSlices([][]float64{{1, -2}, {-3, 4}, {0, 5}})
+
+	type TestCase struct {
+		desc  string
+		input Matrix
+		norm  func(Matrix) float64
+		want  float64
+	}
+
+	test_cases := []TestCase{
+		{desc: "1-norm", input: x, norm: Norm1, want: 11},
+		{desc: "infinity norm", input: x, norm: NormInf, want: 7},
+		{desc: "Frobenius norm", input: x, norm: NormFrobenius, want: math.Sqrt(55)},
+		{desc: "1-norm of a sparse matrix", input: toSparse(x), norm: Norm1, want: 11},
+		{desc: "infinity norm of a diagonal matrix", input: NewDiagonal([]float64{2, -6, 1}), norm: NormInf, want: 6},
+		{desc: "Frobenius norm of a vector", input: Vector{3, 4}, norm: NormFrobenius, want: 5},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got := test_case.norm(test_case.input)
+			if math.Abs(got-test_case.want) > 1e-12 {
+				t.Errorf("expected to be the same, got %v, want %v", got, test_case.want)
+			}
+		})
+	}
+}
+
+func TestNorm2(t *testing.T) {
+	// The singular values of [[3, 0], [4, 5]] are 3 sqrt(5) and sqrt(5)
+	got, err := Norm2(fromSliceOfSlices([][]float64{{3, 0}, {4, 5}}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := 3 * math.Sqrt(5); math.Abs(got-want) > 1e-12 {
+		t.Errorf("expected to be the same, got %v, want %v", got, want)
+	}
+}
+
+func TestCondEstimate(t *testing.T) {
+	// Hilbert matrices are famously badly conditioned
+	hilbert := NewDense(6, 6)
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			hilbert.Set(i, j, 1/float64(i+j+1))
+		}
+	}
+
+	type TestCase struct {
+		desc  string
+		input Matrix
+	}
+
+	test_cases := []TestCase{
+		{desc: "identity", input: Identity(4)},
+		{desc: "diagonal", input: NewDiagonal([]float64{1, 1e-3, 10})},
+		{desc: "needs pivoting", input: fromSliceOfSlices([][]float64{{0, 2, 1}, {1, 1, 0}, {3, 0, 1}})},
+		{desc: "upper triangular", input: fromSliceOfSlices([][]float64{{1, 100, 3}, {0, 1, 40}, {0, 0, 2}})},
+		{desc: "Hilbert", input: hilbert},
+		{desc: "random", input: benchDesign(30, 30)},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got, err := CondEstimate(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			inv, err := Inverse(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			want := Norm1(test_case.input) * Norm1(inv)
+			if got > want*(1+1e-6) || got < want/3 {
+				t.Errorf("expected to be close, got %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("singular", func(t *testing.T) {
+		got, err := CondEstimate(fromSliceOfSlices([][]float64{{1, 2}, {2, 4}}))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !math.IsInf(got, 1) {
+			t.Errorf("expected to be infinite, got %v", got)
+		}
+	})
+
+	t.Run("not square", func(t *testing.T) {
+		if _, err := CondEstimate(NewDense(3, 2)); err == nil {
+			t.Errorf("expected an error for a non-square matrix")
+		}
+	})
+}
+
+func TestSolveTransposedLU(t *testing.T) {
+	a := fromSliceOfSlices([][]float64{{0, 2, 1}, {1, 1, 0}, {3, 0, 1}})
+	lu, err := DecomposeLU(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b := Vector{1, -2, 3}
+	for _, transpose := range []bool{false, true} {
+		z := lu.solveVector(b, transpose)
+		x := a
+		if transpose {
+			x = Transpose(a)
+		}
+		got, _ := MultiplyVector(x, z)
+		if !near(got, b, 1e-12) {
+			t.Errorf("expected to be the same with transpose %v, got %v, want %v", transpose, got, b)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package matrix
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Number of goroutines large products are shared between, GOMAXPROCS unless
+// changed with SetWorkers
+var numWorkers atomic.Int64
+
+func init() {
+	numWorkers.Store(int64(runtime.GOMAXPROCS(0)))
+}
+
+// Returns the number of goroutines large products are shared between
+func Workers() int {
+	return int(numWorkers.Load())
+}
+
+// Share large products between `n` goroutines, 1 to never start any, returning
+// the previous number. It is safe to call while products are running, each
+// reads the number once as it starts so only later products see the change.
+func SetWorkers(n int) int {
+	return int(numWorkers.Swap(int64(max(n, 1))))
+}
+
+// Multiply-adds each worker should have at least, below this starting
+// goroutines costs more than it saves
+const parallelWork = 1 << 16
+
+// Edge of the square tiles of the dense product, so that a tile of each of
+// `x`, `y` and the result stay in cache together
+const blockSize = 128
+
+// The number of workers worth using for `work` multiply-adds
+func workerCount(work int) int {
+	return max(1, min(Workers(), work/parallelWork))
+}
+
+// Length of the blocks to split `n` items into for `workers`, a few each so
+// that uneven blocks even out
+func shareSize(n, workers int) int {
+	return max(1, (n+4*workers-1)/(4*workers))
+}
+
+// Call `fn` on each block [lo, hi) of 0..n, `size` long but for the last,
+// sharing the blocks between `workers` goroutines
+func parallelFor(n, size, workers int, fn func(lo, hi int)) {
+	if workers <= 1 {
+		for lo := 0; lo < n; lo += size {
+			fn(lo, min(lo+size, n))
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				lo := int(next.Add(int64(size))) - size
+				if lo >= n {
+					return
+				}
+				fn(lo, min(lo+size, n))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Dense product, tiled for cache, with blocks of rows shared between workers.
+// With too few rows to go round, as in X'X for tall X, the inner dimension is
+// shared instead and the partial products summed.
+func multiplyDense(x, y *Dense) *Dense {
+	z := NewDense(x.N, y.M)
+	workers := workerCount(x.N * x.M * y.M)
+	if rowBlocks := (x.N + blockSize - 1) / blockSize; rowBlocks >= workers {
+		parallelFor(x.N, blockSize, workers, func(lo, hi int) {
+			multiplyBlock(x, y, z, lo, hi, 0, x.M)
+		})
+		return z
+	}
+
+	size := (x.M + workers - 1) / workers
+	partial := make([]*Dense, workers)
+	parallelFor(x.M, size, workers, func(lo, hi int) {
+		p := NewDense(x.N, y.M)
+		multiplyBlock(x, y, p, 0, x.N, lo, hi)
+		partial[lo/size] = p
+	})
+	for _, p := range partial {
+		if p == nil {
+			continue
+		}
+		for i, v := range p.Values {
+			z.Values[i] += v
+		}
+	}
+	return z
+}
+
+// Add the product of rows [rlo, rhi) and columns [klo, khi) of `x` with the
+// matching rows of `y` to `z`. Within a tile it runs in i-k-j order, so the
+// inner loop is along rows of both `y` and `z`.
+func multiplyBlock(x, y, z *Dense, rlo, rhi, klo, khi int) {
+	for kk := klo; kk < khi; kk += blockSize {
+		kEnd := min(kk+blockSize, khi)
+		for jj := 0; jj < y.M; jj += blockSize {
+			jEnd := min(jj+blockSize, y.M)
+			for i := rlo; i < rhi; i++ {
+				xi := x.Values[i*x.M : (i+1)*x.M]
+				zi := z.Values[i*z.M+jj : i*z.M+jEnd]
+				for k := kk; k < kEnd; k++ {
+					a := xi[k]
+					if a == 0 {
+						continue
+					}
+					for j, b := range y.Values[k*y.M+jj : k*y.M+jEnd] {
+						zi[j] += a * b
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParallelMultiply(t *testing.T) {
+	defer SetWorkers(Workers())
+
+	// A sparse design with an indicator for one of 50 groups on each row
+	indicators := NewDense(20000, 50)
+	for i := 0; i < 20000; i++ {
+		indicators.Set(i, (i*7)%50, 1)
+		indicators.Set(i, 0, float64(i%3))
+	}
+
+	tall := benchDesign(20000, 8)
+	square := benchDesign(300, 300)
+	wide := benchDesign(150, 700)
+
+	type TestCase struct {
+		desc string
+		x, y Matrix
+	}
+
+	test_cases := []TestCase{
+		{desc: "X'X of a tall dense matrix", x: Transpose(tall), y: tall},
+		{desc: "dense square matrices", x: square, y: square},
+		{desc: "dense matrices larger than a tile", x: wide, y: Transpose(wide)},
+		{desc: "CSR matrices", x: ToCSR(Transpose(indicators)), y: ToCSR(indicators)},
+		{desc: "CSC matrices", x: ToCSC(Transpose(indicators)), y: ToCSC(indicators)},
+		{desc: "CSR and dense matrices", x: ToCSR(indicators), y: benchDesign(50, 200)},
+		{desc: "dense and CSC matrices", x: benchDesign(100, 20000), y: ToCSC(indicators)},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			SetWorkers(1)
+			want, err := Multiply(test_case.x, test_case.y)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			SetWorkers(4)
+			got, err := Multiply(test_case.x, test_case.y)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !near(got, want, 1e-9) {
+				t.Errorf("expected the parallel and serial products to be the same")
+			}
+
+			// Against the plain triple loop
+			n, inner := test_case.x.Dims()
+			_, m := test_case.y.Dims()
+			for _, ij := range [][2]int{{0, 0}, {n - 1, m - 1}, {n / 2, m / 3}} {
+				i, j := ij[0], ij[1]
+				v := 0.0
+				for k := 0; k < inner; k++ {
+					v += test_case.x.At(i, k) * test_case.y.At(k, j)
+				}
+				if math.Abs(got.At(i, j)-v) > 1e-9 {
+					t.Errorf("expected to be the same at %d, %d, got %v, want %v", i, j, got.At(i, j), v)
+				}
+			}
+		})
+	}
+}
+
+func TestSetWorkersWhileMultiplying(t *testing.T) {
+	defer SetWorkers(Workers())
+	x := benchDesign(20000, 8)
+	xT := Transpose(x)
+	want, _ := Multiply(xT, x)
+
+	done := make(chan bool)
+	go func() {
+		for k := 0; k < 20; k++ {
+			SetWorkers(1 + k%4)
+		}
+		done <- true
+	}()
+	for k := 0; k < 4; k++ {
+		got, err := Multiply(xT, x)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !near(got, want, 1e-9) {
+			t.Errorf("expected the product not to depend on the number of workers")
+		}
+	}
+	<-done
+
+	if got := SetWorkers(0); got != 4 || Workers() != 1 {
+		t.Errorf("expected the previous 4 and at least 1 worker, got %d and %d", got, Workers())
+	}
+}
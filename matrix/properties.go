@@ -1,42 +1,55 @@
 package matrix
 
 func IsDiagonal(x Matrix) (bool, error) {
-	if b, err := x.isSquare(); !b {
+	if b, err := isSquare(x); !b {
 		return false, err
 	}
+	if _, ok := x.(*Diagonal); ok {
+		return true, nil
+	}
 
-	for i := 0; i < x.N; i++ {
-		for j := 0; j < x.M; j++ {
-			if i == j { // Skip on diagonal entries
-				continue
-			}
-			if x.Get(i, j) != 0 {
-				return false, nil
-			}
+	diagonal := true
+	x.NonZeros(func(i, j int, v float64) {
+		if i != j {
+			diagonal = false
 		}
-	}
+	})
 
-	return true, nil
+	return diagonal, nil
 }
 
 func IsUpperTriangular(x Matrix) (bool, error) {
-	if b, err := x.isSquare(); !b {
+	if b, err := isSquare(x); !b {
 		return false, err
 	}
-
-	for i := 0; i < x.N; i++ {
-		for j := 0; j < x.M; j++ {
-			if i > j {
-				if x.Get(i, j) != 0 {
-					return false, nil
-				}
-			}
+	switch x := x.(type) {
+	case *Diagonal:
+		return true, nil
+	case *Triangular:
+		if x.Upper {
+			return true, nil
 		}
 	}
 
-	return true, nil
+	upper := true
+	x.NonZeros(func(i, j int, v float64) {
+		if i > j {
+			upper = false
+		}
+	})
+
+	return upper, nil
 }
 
 func IsLowerTriangular(x Matrix) (bool, error) {
 	return IsUpperTriangular(Transpose(x))
 }
+
+// Whether `x` is positive definite, assuming it is symmetric
+func IsPositiveDefinite(x Matrix) (bool, error) {
+	if b, err := isSquare(x); !b {
+		return false, err
+	}
+	_, err := DecomposeCholesky(x)
+	return err == nil, nil
+}
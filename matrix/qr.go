@@ -0,0 +1,300 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+/*
+The QR decomposition of an `N` x `M` matrix with `N` >= `M`, computed by
+Householder reflections. `A = QR` where Q is `N` x `M` with orthonormal columns
+and R is `M` x `M` upper triangular.
+
+The Householder vectors are stored below the diagonal of `qr` and the strict
+upper triangle of R above it, with the diagonal of R kept in `rdiag`.
+*/
+type QR struct {
+	qr    []float64 // Row major
+	rdiag []float64
+	N, M  int
+}
+
+// Relative size of a diagonal element of R, compared with the largest, below
+// which a matrix is considered rank deficient. This is the default of R's lm.
+var RankTolerance = 1e-7
+
+// Decompose `x` into QR by Householder reflections
+func DecomposeQR(x Matrix) (QR, error) {
+	n, m := x.Dims()
+	if n < m {
+		return QR{}, fmt.Errorf("Expected at least as many rows as columns, got a %d x %d", n, m)
+	}
+
+	qr := toDense(x)
+	rdiag := make([]float64, m)
+
+	for k := 0; k < m; k++ {
+		// Norm of the k-th column below the diagonal, hypot avoids overflow
+		nrm := 0.0
+		for i := k; i < n; i++ {
+			nrm = math.Hypot(nrm, qr[i*m+k])
+		}
+
+		if nrm != 0 {
+			// Form the k-th Householder vector
+			if qr[k*m+k] < 0 {
+				nrm = -nrm
+			}
+			for i := k; i < n; i++ {
+				qr[i*m+k] /= nrm
+			}
+			qr[k*m+k] += 1
+
+			// Apply the reflection to the remaining columns
+			for j := k + 1; j < m; j++ {
+				s := 0.0
+				for i := k; i < n; i++ {
+					s += qr[i*m+k] * qr[i*m+j]
+				}
+				s = -s / qr[k*m+k]
+				for i := k; i < n; i++ {
+					qr[i*m+j] += s * qr[i*m+k]
+				}
+			}
+		}
+		rdiag[k] = -nrm
+	}
+
+	return QR{qr: qr, rdiag: rdiag, N: n, M: m}, nil
+}
+
+// Whether R has no (relatively) zero elements on its diagonal
+func (f QR) IsFullRank() bool {
+	biggest := 0.0
+	for _, d := range f.rdiag {
+		biggest = max(biggest, math.Abs(d))
+	}
+	for _, d := range f.rdiag {
+		if math.Abs(d) <= RankTolerance*biggest || d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the `M` x `M` upper triangular factor R
+func (f QR) R() Matrix {
+	m := f.M
+	r := make([]float64, m*m)
+	for i := 0; i < m; i++ {
+		r[i*m+i] = f.rdiag[i]
+		for j := i + 1; j < m; j++ {
+			r[i*m+j] = f.qr[i*m+j]
+		}
+	}
+	return fromDense(m, m, r)
+}
+
+// Returns the `N` x `M` orthonormal factor Q
+func (f QR) Q() Matrix {
+	n, m := f.N, f.M
+	q := make([]float64, n*m)
+	for k := m - 1; k >= 0; k-- {
+		q[k*m+k] = 1
+		for j := k; j < m; j++ {
+			if f.qr[k*m+k] == 0 {
+				continue
+			}
+			s := 0.0
+			for i := k; i < n; i++ {
+				s += f.qr[i*m+k] * q[i*m+j]
+			}
+			s = -s / f.qr[k*m+k]
+			for i := k; i < n; i++ {
+				q[i*m+j] += s * f.qr[i*m+k]
+			}
+		}
+	}
+	return fromDense(n, m, q)
+}
+
+// Apply Q' to the columns of `b` in place, `b` is `N` x `cols` and row major
+func (f QR) applyQT(b []float64, cols int) {
+	n, m := f.N, f.M
+	for k := 0; k < m; k++ {
+		if f.qr[k*m+k] == 0 {
+			continue
+		}
+		for j := 0; j < cols; j++ {
+			s := 0.0
+			for i := k; i < n; i++ {
+				s += f.qr[i*m+k] * b[i*cols+j]
+			}
+			s = -s / f.qr[k*m+k]
+			for i := k; i < n; i++ {
+				b[i*cols+j] += s * f.qr[i*m+k]
+			}
+		}
+	}
+}
+
+// Returns the least squares solution X minimising ||AX - b||, where A = QR,
+// by solving R X = Q'b
+func (f QR) Solve(b Matrix) (Matrix, error) {
+	bn, cols := b.Dims()
+	if bn != f.N {
+		return nil, fmt.Errorf("Expected `b` to have %d rows, got %d", f.N, bn)
+	}
+	if !f.IsFullRank() {
+		return nil, fmt.Errorf("Matrix is rank deficient")
+	}
+
+	m := f.M
+	z := toDense(b)
+	f.applyQT(z, cols)
+
+	// Back substitution with R
+	for k := m - 1; k >= 0; k-- {
+		for j := 0; j < cols; j++ {
+			z[k*cols+j] /= f.rdiag[k]
+		}
+		for i := 0; i < k; i++ {
+			for j := 0; j < cols; j++ {
+				z[i*cols+j] -= z[k*cols+j] * f.qr[i*m+k]
+			}
+		}
+	}
+
+	return fromDense(m, cols, z[:m*cols]), nil
+}
+
+// Returns the inverse of R, for instance (X'X)^-1 = R^-1 R^-T
+func (f QR) RInverse() (Matrix, error) {
+	if !f.IsFullRank() {
+		return nil, fmt.Errorf("Matrix is rank deficient")
+	}
+
+	m := f.M
+	z := make([]float64, m*m)
+	// Column j of the inverse solves R z = e_j, which is zero below row j
+	for j := 0; j < m; j++ {
+		z[j*m+j] = 1 / f.rdiag[j]
+		for i := j - 1; i >= 0; i-- {
+			s := 0.0
+			for k := i + 1; k <= j; k++ {
+				s += f.qr[i*m+k] * z[k*m+j]
+			}
+			z[i*m+j] = -s / f.rdiag[i]
+		}
+	}
+	return fromDense(m, m, z), nil
+}
+
+/*
+A QR decomposition with the linearly dependent columns of A moved to the end,
+`AP = QR`, like LINPACK's dqrdc2 used by R's lm. Columns are taken in order,
+and one is moved to the end when what is left of it, after removing the
+earlier columns, is negligible compared with its original norm.
+
+The embedded QR is of the first `Rank` columns of AP, which are linearly
+independent, so its R is invertible.
+*/
+type PivotedQR struct {
+	QR
+	pivot []int
+}
+
+// Decompose `x` into QR, moving dependent columns to the end
+func DecomposePivotedQR(x Matrix) (PivotedQR, error) {
+	n, m := x.Dims()
+	if n < m {
+		return PivotedQR{}, fmt.Errorf("Expected at least as many rows as columns, got a %d x %d", n, m)
+	}
+
+	qr := toDense(x)
+	rdiag := make([]float64, m)
+	pivot := make([]int, m)
+	norms := make([]float64, m)
+	for j := 0; j < m; j++ {
+		pivot[j] = j
+		for i := 0; i < n; i++ {
+			norms[j] = math.Hypot(norms[j], qr[i*m+j])
+		}
+	}
+
+	// Columns from `rank` onwards have been found to be dependent
+	rank := m
+	for k := 0; k < rank; {
+		nrm := 0.0
+		for i := k; i < n; i++ {
+			nrm = math.Hypot(nrm, qr[i*m+k])
+		}
+
+		if nrm == 0 || nrm <= RankTolerance*norms[k] {
+			// Move column k to the end, and look at the next one in its place
+			for i := 0; i < n; i++ {
+				row := qr[i*m : (i+1)*m]
+				v := row[k]
+				copy(row[k:], row[k+1:])
+				row[m-1] = v
+			}
+			p, nk := pivot[k], norms[k]
+			copy(pivot[k:], pivot[k+1:])
+			copy(norms[k:], norms[k+1:])
+			pivot[m-1], norms[m-1] = p, nk
+			rank--
+			continue
+		}
+
+		// Householder reflection as in DecomposeQR, applied to all later columns
+		if qr[k*m+k] < 0 {
+			nrm = -nrm
+		}
+		for i := k; i < n; i++ {
+			qr[i*m+k] /= nrm
+		}
+		qr[k*m+k] += 1
+		for j := k + 1; j < m; j++ {
+			s := 0.0
+			for i := k; i < n; i++ {
+				s += qr[i*m+k] * qr[i*m+j]
+			}
+			s = -s / qr[k*m+k]
+			for i := k; i < n; i++ {
+				qr[i*m+j] += s * qr[i*m+k]
+			}
+		}
+		rdiag[k] = -nrm
+		k++
+	}
+
+	// The first `rank` columns only depend on their own reflections, so are
+	// the packed QR of those columns alone
+	packed := make([]float64, n*rank)
+	for i := 0; i < n; i++ {
+		copy(packed[i*rank:(i+1)*rank], qr[i*m:i*m+rank])
+	}
+
+	return PivotedQR{
+		QR:    QR{qr: packed, rdiag: rdiag[:rank], N: n, M: rank},
+		pivot: pivot,
+	}, nil
+}
+
+// Returns the number of linearly independent columns
+func (f PivotedQR) Rank() int {
+	return f.QR.M
+}
+
+// Returns the original column of each column of AP, the first `Rank` are independent
+func (f PivotedQR) Pivot() []int {
+	return slices.Clone(f.pivot)
+}
+
+// Returns the original columns which depend on earlier ones, in order
+func (f PivotedQR) Aliased() []int {
+	z := slices.Clone(f.pivot[f.Rank():])
+	slices.Sort(z)
+	return z
+}
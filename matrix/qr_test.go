@@ -0,0 +1,255 @@
+package matrix
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Helper to compare matrices with a tolerance suitable for decompositions
+func near(x, y Matrix, tol float64) bool {
+	xn, xm := x.Dims()
+	yn, ym := y.Dims()
+	if xn != yn || xm != ym {
+		return false
+	}
+	for i := 0; i < xn; i++ {
+		for j := 0; j < xm; j++ {
+			if math.Abs(x.At(i, j)-y.At(i, j)) > tol {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestDecomposeQR(t *testing.T) {
+	type TestCase struct {
+		desc  string
+		input Matrix
+	}
+
+	test_cases := []TestCase{
+		{
+			desc: "QR of a 3x3 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{12, -51, 4},
+				{6, 167, -68},
+				{-4, 24, -41},
+			}),
+		},
+		{
+			desc: "QR of a tall 5x2 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 1},
+				{1, 2},
+				{1, 3},
+				{1, 4},
+				{1, 5},
+			}),
+		},
+		{
+			desc: "QR of a matrix with a zero column",
+			input: fromSliceOfSlices([][]float64{
+				{0, 2},
+				{0, 1},
+				{0, 3},
+			}),
+		},
+	}
+
+	t.Run("fail on wide matrices", func(t *testing.T) {
+		m := fromSliceOfSlices([][]float64{
+			{1, 2, 3},
+		})
+		_, err := DecomposeQR(m)
+		if err == nil {
+			t.Errorf("expected QR to fail")
+		}
+	})
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			qr, err := DecomposeQR(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			q, r := qr.Q(), qr.R()
+
+			if b, _ := IsUpperTriangular(r); !b {
+				t.Errorf("expected R to be upper triangular, got %v", r)
+			}
+
+			got, _ := Multiply(q, r)
+			if !near(got, test_case.input, 1e-12) {
+				t.Errorf("expected QR = A, got %v, want %v", got, test_case.input)
+			}
+
+			if !qr.IsFullRank() {
+				return // Q is only orthonormal on the range of A
+			}
+			_, m := q.Dims()
+			qtq, _ := Multiply(Transpose(q), q)
+			if !near(qtq, Identity(m), 1e-12) {
+				t.Errorf("expected Q'Q = I, got %v", qtq)
+			}
+		})
+	}
+}
+
+func TestQRSolve(t *testing.T) {
+	t.Run("least squares line", func(t *testing.T) {
+		x := fromSliceOfSlices([][]float64{
+			{1, 1},
+			{1, 2},
+			{1, 3},
+			{1, 4},
+			{1, 5},
+		})
+		y := fromSliceOfSlices([][]float64{{1}, {3}, {2}, {5}, {4}})
+		qr, _ := DecomposeQR(x)
+
+		got, err := qr.Solve(y)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := fromSliceOfSlices([][]float64{{0.6}, {0.8}})
+		if !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("square system", func(t *testing.T) {
+		a := fromSliceOfSlices([][]float64{
+			{1, 2, 3},
+			{1, 2, 1},
+			{1, 1, 4},
+		})
+		b := fromSliceOfSlices([][]float64{{1, 0}, {0, 1}, {0, 0}})
+		qr, _ := DecomposeQR(a)
+
+		got, err := qr.Solve(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := fromSliceOfSlices([][]float64{{-3.5, 2.5}, {1.5, -0.5}, {0.5, -0.5}})
+		if !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("fail on rank deficient matrices", func(t *testing.T) {
+		x := fromSliceOfSlices([][]float64{
+			{1, 2},
+			{2, 4},
+			{3, 6},
+		})
+		qr, _ := DecomposeQR(x)
+		if qr.IsFullRank() {
+			t.Errorf("expected matrix to be rank deficient")
+		}
+		if _, err := qr.Solve(Zero(3, 1)); err == nil {
+			t.Errorf("expected solve to fail")
+		}
+	})
+}
+
+func TestQRRInverse(t *testing.T) {
+	a := fromSliceOfSlices([][]float64{
+		{12, -51, 4},
+		{6, 167, -68},
+		{-4, 24, -41},
+	})
+	qr, _ := DecomposeQR(a)
+
+	got, err := qr.RInverse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	id, _ := Multiply(qr.R(), got)
+	if !near(id, Identity(3), 1e-12) {
+		t.Errorf("expected R R^-1 = I, got %v", id)
+	}
+}
+
+func TestDecomposePivotedQR(t *testing.T) {
+	type TestCase struct {
+		desc    string
+		input   Matrix
+		aliased []int
+	}
+
+	test_cases := []TestCase{
+		{
+			desc: "full rank matrices aren't pivoted",
+			input: fromSliceOfSlices([][]float64{
+				{12, -51, 4},
+				{6, 167, -68},
+				{-4, 24, -41},
+			}),
+			aliased: []int{},
+		},
+		{
+			desc: "a duplicated column",
+			input: fromSliceOfSlices([][]float64{
+				{1, 1, 2, 1},
+				{1, 2, 4, 3},
+				{1, 3, 6, 2},
+				{1, 4, 8, 5},
+				{1, 5, 10, 4},
+			}),
+			aliased: []int{2},
+		},
+		{
+			desc: "a column which is a sum of the others, and a zero column",
+			input: fromSliceOfSlices([][]float64{
+				{0, 1, 0, 1, 3},
+				{0, 0, 1, 1, 1},
+				{0, 1, 0, 1, 4},
+				{0, 0, 1, 1, 1},
+				{0, 1, 0, 1, 5},
+			}),
+			aliased: []int{0, 3},
+		},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			qr, err := DecomposePivotedQR(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			_, m := test_case.input.Dims()
+			if got, want := qr.Rank(), m-len(test_case.aliased); got != want {
+				t.Errorf("expected to be the same, got %v, want %v", got, want)
+			}
+			if got := qr.Aliased(); !reflect.DeepEqual(got, test_case.aliased) {
+				t.Errorf("expected to be the same, got %v, want %v", got, test_case.aliased)
+			}
+
+			// QR is of the independent columns
+			var kept [][]float64
+			n, _ := test_case.input.Dims()
+			for i := 0; i < n; i++ {
+				var row []float64
+				for _, j := range qr.Pivot()[:qr.Rank()] {
+					row = append(row, test_case.input.At(i, j))
+				}
+				kept = append(kept, row)
+			}
+			if !qr.IsFullRank() {
+				t.Errorf("expected the kept columns to be full rank")
+			}
+			got, _ := Multiply(qr.Q(), qr.R())
+			if want := fromSliceOfSlices(kept); !near(got, want, 1e-12) {
+				t.Errorf("expected QR = AP, got %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("fail on wide matrices", func(t *testing.T) {
+		if _, err := DecomposePivotedQR(Zero(1, 2)); err == nil {
+			t.Errorf("expected QR to fail")
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+)
+
+// Returns X solving AX = `b` for square `a`, by LU decomposition rather than
+// forming the inverse of `a`
+func Solve(a, b Matrix) (Matrix, error) {
+	if ok, err := isSquare(a); !ok {
+		return nil, err
+	}
+	lu, err := DecomposeLU(a)
+	if err != nil {
+		return nil, err
+	}
+	if k := lu.singularColumn(); k >= 0 {
+		return nil, fmt.Errorf("Matrix is singular, column %d is a linear combination of earlier columns", k)
+	}
+	return lu.Solve(b)
+}
+
+// The first column whose pivot is zero, relative to the largest element of U
+// and so to working precision, or -1 if there isn't one
+func (f LU) singularColumn() int {
+	biggest := 0.0
+	for _, v := range f.lu {
+		biggest = max(biggest, math.Abs(v))
+	}
+	tol := float64(f.N) * epsilon * biggest
+	for k := 0; k < f.N; k++ {
+		if d := math.Abs(f.lu[k*f.N+k]); d == 0 || d <= tol {
+			return k
+		}
+	}
+	return -1
+}
+
+// Returns X solving AX = `b` for upper or lower triangular `a`, by back or
+// forward substitution
+func SolveTriangular(a, b Matrix) (Matrix, error) {
+	upper, err := IsUpperTriangular(a)
+	if err != nil {
+		return nil, err
+	}
+	lower := false
+	if !upper {
+		if lower, err = IsLowerTriangular(a); err != nil {
+			return nil, err
+		}
+	}
+	if !upper && !lower {
+		return nil, fmt.Errorf("Expected a triangular matrix")
+	}
+
+	n, _ := a.Dims()
+	bn, cols := b.Dims()
+	if bn != n {
+		return nil, fmt.Errorf("Expected `b` to have %d rows, got %d", n, bn)
+	}
+
+	t := make([]float64, n*n)
+	a.NonZeros(func(i, j int, v float64) {
+		t[i*n+j] = v
+	})
+	for k := 0; k < n; k++ {
+		if t[k*n+k] == 0 {
+			return nil, fmt.Errorf("Matrix is singular, zero on the diagonal at %d", k)
+		}
+	}
+
+	z := toDense(b)
+	for step := 0; step < n; step++ {
+		// Back substitution from the bottom, forward substitution from the top
+		k := step
+		if upper {
+			k = n - 1 - step
+		}
+		for j := 0; j < cols; j++ {
+			z[k*cols+j] /= t[k*n+k]
+		}
+		for i := 0; i < n; i++ {
+			l := t[i*n+k]
+			if i == k || l == 0 {
+				continue
+			}
+			for j := 0; j < cols; j++ {
+				z[i*cols+j] -= l * z[k*cols+j]
+			}
+		}
+	}
+
+	return fromDense(n, cols, z), nil
+}
+
+// Returns the least squares solution X minimising ||AX - b|| for `a` with at
+// least as many rows as columns, by QR decomposition
+func SolveLeastSquares(a, b Matrix) (Matrix, error) {
+	qr, err := DecomposePivotedQR(a)
+	if err != nil {
+		return nil, err
+	}
+	if aliased := qr.Aliased(); len(aliased) > 0 {
+		return nil, fmt.Errorf("Matrix is rank deficient, columns %v are linear combinations of earlier columns", aliased)
+	}
+	// With no dependent columns none have moved, so this is the QR of `a`
+	return qr.QR.Solve(b)
+}
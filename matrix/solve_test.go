@@ -0,0 +1,168 @@
+package matrix
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	a := fromSliceOfSlices([][]float64{
+		{0, 2, 1},
+		{1, 1, 1},
+		{4, 2, 5},
+	})
+	b := fromSliceOfSlices([][]float64{
+		{1, 2},
+		{0, 1},
+		{3, -1},
+	})
+
+	t.Run("solve a square system", func(t *testing.T) {
+		x, err := Solve(a, b)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ax, _ := Multiply(a, x)
+		if !near(ax, b, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", ax, b)
+		}
+	})
+
+	t.Run("agree with the inverse", func(t *testing.T) {
+		x, _ := Solve(a, b)
+		inv, _ := Inverse(a)
+		want, _ := Multiply(inv, b)
+		if !near(x, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", x, want)
+		}
+	})
+
+	t.Run("fail on singular matrices", func(t *testing.T) {
+		singular := fromSliceOfSlices([][]float64{
+			{1, 2, 3},
+			{2, 4, 6},
+			{1, 0, 1},
+		})
+		_, err := Solve(singular, b)
+		if err == nil || !strings.Contains(err.Error(), "singular") {
+			t.Errorf("expected a singular matrix error, got %v", err)
+		}
+	})
+
+	t.Run("fail on matrices singular to working precision", func(t *testing.T) {
+		nearly := fromSliceOfSlices([][]float64{
+			{1, 2, 3},
+			{4, 5, 6},
+			{7, 8, 9},
+		})
+		_, err := Solve(nearly, b)
+		if err == nil || !strings.Contains(err.Error(), "column 2") {
+			t.Errorf("expected a singular matrix error, got %v", err)
+		}
+	})
+
+	t.Run("fail on non-square matrices", func(t *testing.T) {
+		_, err := Solve(NewDense(3, 2), b)
+		if err == nil {
+			t.Errorf("expected an error for a non-square matrix")
+		}
+	})
+
+	t.Run("fail on mismatched dimensions", func(t *testing.T) {
+		_, err := Solve(a, NewDense(2, 1))
+		if err == nil {
+			t.Errorf("expected an error for mismatched dimensions")
+		}
+	})
+}
+
+func TestSolveTriangular(t *testing.T) {
+	upper := NewTriangular(3, true)
+	lower := NewTriangular(3, false)
+	for i := 0; i < 3; i++ {
+		for j := i; j < 3; j++ {
+			upper.Set(i, j, float64(i+j+1))
+			lower.Set(j, i, float64(i-j+2))
+		}
+	}
+	b := fromSliceOfSlices([][]float64{{1}, {2}, {3}})
+
+	type TestCase struct {
+		desc  string
+		input Matrix
+	}
+
+	test_cases := []TestCase{
+		{desc: "solve an upper triangular system", input: upper},
+		{desc: "solve a lower triangular system", input: lower},
+		{desc: "solve a dense upper triangular system", input: fromDense(3, 3, toDense(upper))},
+		{desc: "solve a dense lower triangular system", input: fromDense(3, 3, toDense(lower))},
+		{desc: "solve a diagonal system", input: NewDiagonal([]float64{2, -1, 4})},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			x, err := SolveTriangular(test_case.input, b)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			ax, _ := Multiply(test_case.input, x)
+			if !near(ax, b, 1e-12) {
+				t.Errorf("expected to be the same, got %v, want %v", ax, b)
+			}
+		})
+	}
+
+	t.Run("fail on a zero on the diagonal", func(t *testing.T) {
+		_, err := SolveTriangular(NewDiagonal([]float64{2, 0, 4}), b)
+		if err == nil || !strings.Contains(err.Error(), "singular") {
+			t.Errorf("expected a singular matrix error, got %v", err)
+		}
+	})
+
+	t.Run("fail on matrices which aren't triangular", func(t *testing.T) {
+		_, err := SolveTriangular(fromSliceOfSlices([][]float64{{1, 2}, {3, 4}}), NewDense(2, 1))
+		if err == nil {
+			t.Errorf("expected an error for a matrix which isn't triangular")
+		}
+	})
+}
+
+func TestSolveLeastSquares(t *testing.T) {
+	t.Run("solve an overdetermined system", func(t *testing.T) {
+		x := benchDesign(30, 4)
+		y := NewDense(30, 1)
+		for i := 0; i < 30; i++ {
+			y.Set(i, 0, float64(i%7)-2)
+		}
+		got, err := SolveLeastSquares(x, y)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		svd, _ := DecomposeSVD(x)
+		want, _ := svd.Solve(y)
+		if !near(got, want, 1e-9) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("fail on collinear columns", func(t *testing.T) {
+		x := fromSliceOfSlices([][]float64{
+			{1, 1, 2},
+			{1, 2, 4},
+			{1, 3, 6},
+			{1, 4, 8},
+		})
+		_, err := SolveLeastSquares(x, NewDense(4, 1))
+		if err == nil || !strings.Contains(err.Error(), "[2]") {
+			t.Errorf("expected a rank deficient error naming column 2, got %v", err)
+		}
+	})
+
+	t.Run("fail on wide matrices", func(t *testing.T) {
+		_, err := SolveLeastSquares(NewDense(2, 3), NewDense(2, 1))
+		if err == nil {
+			t.Errorf("expected an error for a wide matrix")
+		}
+	})
+}
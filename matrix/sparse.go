@@ -0,0 +1,97 @@
+package matrix
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+/*
+A Sparse matrix contains a map of values, indexed by a 2-dim array representing
+the coordinates of the Matrix. Where `Values[{0,0}]` is the top leftmost
+element and `Values[{N-1,M-1}]` is the bottom rightmost element. Only non-zero
+elements are stored.
+*/
+type Sparse struct {
+	Values map[[2]int]float64
+	// Number of Rows and Columns
+	N, M int
+}
+
+// Create a `n` x `m` sparse matrix of zeros
+func NewSparse(n, m int) *Sparse {
+	return &Sparse{
+		Values: make(map[[2]int]float64),
+		N:      n,
+		M:      m,
+	}
+}
+
+func (x *Sparse) Dims() (int, int) {
+	return x.N, x.M
+}
+
+// Get the value in a matrix at a point
+func (x *Sparse) At(i, j int) float64 {
+	return x.Values[[2]int{i, j}]
+}
+
+// Set a matrix value at a point
+func (x *Sparse) Set(i, j int, v float64) error {
+	if i >= x.N || j >= x.M || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	if v == 0 {
+		delete(x.Values, [2]int{i, j})
+		return nil
+	}
+	x.Values[[2]int{i, j}] = v
+	return nil
+}
+
+// Call `fn` for each non-zero element, in row major order
+func (x *Sparse) NonZeros(fn func(i, j int, v float64)) {
+	keys := make([][2]int, 0, len(x.Values))
+	for k := range x.Values {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
+		}
+		return cmp.Compare(a[1], b[1])
+	})
+	for _, k := range keys {
+		fn(k[0], k[1], x.Values[k])
+	}
+}
+
+// Add to a value at a point
+func (x *Sparse) Update(i, j int, v float64) error {
+	if i >= x.N || j >= x.M || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	if v == 0 {
+		return nil // Don't want this to make new empty elements
+	}
+	x.Values[[2]int{i, j}] += v
+	return nil
+}
+
+// Copy a matrix in it's entirety
+func (x *Sparse) Copy() *Sparse {
+	return &Sparse{
+		Values: maps.Clone(x.Values), // OK to use as we are a shallow map
+		N:      x.N,
+		M:      x.M,
+	}
+}
+
+// The proportion of elements which are non-zero
+func (x *Sparse) Density() float64 {
+	if x.N == 0 || x.M == 0 {
+		return 0
+	}
+	return float64(len(x.Values)) / (float64(x.N) * float64(x.M))
+}
@@ -0,0 +1,160 @@
+package matrix
+
+import "testing"
+
+func TestStructured(t *testing.T) {
+	type TestCase struct {
+		desc  string
+		input Matrix
+		dense Matrix
+	}
+
+	diagonal := NewDiagonal([]float64{2, -1, 4})
+
+	upper := NewTriangular(3, true)
+	lower := NewTriangular(3, false)
+	for i, row := range [][]float64{{1, 2, 3}, {0, 4, 5}, {0, 0, 6}} {
+		for j, v := range row {
+			upper.Set(i, j, v)
+			lower.Set(j, i, v)
+		}
+	}
+
+	parent := fromSliceOfSlices([][]float64{
+		{9, 9, 9, 9},
+		{9, 1, 2, 3},
+		{9, 1, 2, 1},
+		{9, 1, 1, 4},
+	})
+	view, err := NewView(parent, 1, 1, 3, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	test_cases := []TestCase{
+		{
+			desc:  "diagonal",
+			input: diagonal,
+			dense: fromSliceOfSlices([][]float64{{2, 0, 0}, {0, -1, 0}, {0, 0, 4}}),
+		},
+		{
+			desc:  "upper triangular",
+			input: upper,
+			dense: fromSliceOfSlices([][]float64{{1, 2, 3}, {0, 4, 5}, {0, 0, 6}}),
+		},
+		{
+			desc:  "lower triangular",
+			input: lower,
+			dense: fromSliceOfSlices([][]float64{{1, 0, 0}, {2, 4, 0}, {3, 5, 6}}),
+		},
+		{
+			desc:  "view",
+			input: view,
+			dense: fromSliceOfSlices([][]float64{{1, 2, 3}, {1, 2, 1}, {1, 1, 4}}),
+		},
+		{
+			desc:  "sparse",
+			input: toSparse(fromSliceOfSlices([][]float64{{0, 2, 0}, {1, 0, 0}, {0, 0, 3}})),
+			dense: fromSliceOfSlices([][]float64{{0, 2, 0}, {1, 0, 0}, {0, 0, 3}}),
+		},
+	}
+
+	other := fromSliceOfSlices([][]float64{{1, 2, 0}, {0, 1, 3}, {4, 0, 1}})
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			if !Equal(test_case.input, test_case.dense) {
+				t.Fatalf("expected to be the same, got %v, want %v", test_case.input, test_case.dense)
+			}
+
+			got, _ := Multiply(test_case.input, other)
+			want, _ := Multiply(test_case.dense, other)
+			if !Equal(got, want) {
+				t.Errorf("expected products to be the same, got %v, want %v", got, want)
+			}
+			got, _ = Multiply(other, test_case.input)
+			want, _ = Multiply(other, test_case.dense)
+			if !Equal(got, want) {
+				t.Errorf("expected products to be the same, got %v, want %v", got, want)
+			}
+
+			got, _ = Add(test_case.input, other)
+			want, _ = Add(test_case.dense, other)
+			if !Equal(got, want) {
+				t.Errorf("expected sums to be the same, got %v, want %v", got, want)
+			}
+
+			if got, want := Transpose(test_case.input), Transpose(test_case.dense); !Equal(got, want) {
+				t.Errorf("expected transposes to be the same, got %v, want %v", got, want)
+			}
+
+			got, err := Inverse(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			want, _ = Inverse(test_case.dense)
+			if !near(got, want, 1e-12) {
+				t.Errorf("expected inverses to be the same, got %v, want %v", got, want)
+			}
+
+			got, _, err = GaussianElimination(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			want, _, _ = GaussianElimination(test_case.dense)
+			if !near(got, want, 1e-12) {
+				t.Errorf("expected echelon forms to be the same, got %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("structure is kept", func(t *testing.T) {
+		if _, ok := Transpose(diagonal).(*Diagonal); !ok {
+			t.Errorf("expected a diagonal matrix, got %T", Transpose(diagonal))
+		}
+		z, ok := Transpose(upper).(*Triangular)
+		if !ok || z.Upper {
+			t.Errorf("expected a lower triangular matrix, got %v", Transpose(upper))
+		}
+		if _, ok := Scale(lower, 2).(*Triangular); !ok {
+			t.Errorf("expected a triangular matrix, got %T", Scale(lower, 2))
+		}
+	})
+
+	t.Run("structure is enforced", func(t *testing.T) {
+		if err := diagonal.Set(0, 1, 1); err == nil {
+			t.Errorf("expected setting off the diagonal to fail")
+		}
+		if err := upper.Set(2, 0, 1); err == nil {
+			t.Errorf("expected setting below the diagonal to fail")
+		}
+		if err := upper.Set(2, 0, 0); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("views share their parent", func(t *testing.T) {
+		v, _ := NewView(parent, 2, 0, 2, 2)
+		v.Set(0, 0, 7)
+		if got := parent.At(2, 0); got != 7 {
+			t.Errorf("expected to be the same, got %v, want %v", got, 7)
+		}
+		if _, err := NewView(parent, 3, 3, 2, 2); err == nil {
+			t.Errorf("expected a view outside the matrix to fail")
+		}
+	})
+
+	t.Run("non-zeros are in row major order", func(t *testing.T) {
+		x := toSparse(fromSliceOfSlices([][]float64{{0, 2, 1}, {1, 0, 0}, {0, 5, 3}}))
+		var got []float64
+		x.NonZeros(func(i, j int, v float64) {
+			got = append(got, v)
+		})
+		want := []float64{2, 1, 1, 5, 3}
+		for k := range want {
+			if k >= len(got) || got[k] != want[k] {
+				t.Fatalf("expected to be the same, got %v, want %v", got, want)
+			}
+		}
+	})
+}
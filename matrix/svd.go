@@ -0,0 +1,328 @@
+package matrix
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+/*
+The singular value decomposition of an `N` x `M` matrix, `A = U S V'` where the
+singular values on the diagonal of S are non-negative and decreasing, and U
+and V have orthonormal columns. With K = min(N, M) the thin decomposition has
+U `N` x K and V `M` x K, the full one completes them to square matrices.
+
+It is computed by one-sided Jacobi rotations, which orthogonalise the columns
+of A (or A' when it is wide) directly. This is slower than bidiagonalisation
+but simple, and accurate for small singular values.
+*/
+type SVD struct {
+	u, v [][]float64 // Columns of U and V
+	s    []float64
+	N, M int
+}
+
+// Most sweeps of rotations before giving up, Jacobi converges quadratically so
+// this is generous
+const maxSweeps = 60
+
+// Decompose `x` into U S V'
+func DecomposeSVD(x Matrix) (SVD, error) {
+	n, m := x.Dims()
+	wide := n < m
+	if wide {
+		x = Transpose(x)
+		n, m = m, n
+	}
+
+	// w holds the columns of A as contiguous rows, v starts as the identity
+	w := make([][]float64, m)
+	v := make([][]float64, m)
+	for j := range w {
+		w[j] = make([]float64, n)
+		v[j] = make([]float64, m)
+		v[j][j] = 1
+	}
+	x.NonZeros(func(i, j int, a float64) {
+		w[j][i] = a
+	})
+
+	converged := false
+	for sweep := 0; sweep < maxSweeps && !converged; sweep++ {
+		converged = true
+		for p := 0; p < m-1; p++ {
+			for q := p + 1; q < m; q++ {
+				alpha, beta, gamma := 0.0, 0.0, 0.0
+				for i := 0; i < n; i++ {
+					alpha += w[p][i] * w[p][i]
+					beta += w[q][i] * w[q][i]
+					gamma += w[p][i] * w[q][i]
+				}
+				if gamma == 0 || math.Abs(gamma) <= epsilon*math.Sqrt(alpha*beta) {
+					continue // Already orthogonal
+				}
+				converged = false
+
+				// The rotation which makes columns p and q orthogonal
+				zeta := (beta - alpha) / (2 * gamma)
+				t := 1 / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				if zeta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+				rotate(w[p], w[q], c, s)
+				rotate(v[p], v[q], c, s)
+			}
+		}
+	}
+	if !converged {
+		return SVD{}, fmt.Errorf("SVD did not converge in %d sweeps", maxSweeps)
+	}
+
+	// The singular values are the norms of the orthogonal columns
+	s := make([]float64, m)
+	for j := range w {
+		s[j] = norm(w[j])
+	}
+	order := make([]int, m)
+	for j := range order {
+		order[j] = j
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(s[b], s[a]) })
+
+	f := SVD{s: make([]float64, m), u: make([][]float64, m), v: make([][]float64, m)}
+	tol := 0.0
+	if m > 0 {
+		tol = svdTolerance(n, m, s[order[0]])
+	}
+	for k, j := range order {
+		f.s[k], f.v[k] = s[j], v[j]
+		// Columns with (numerically) zero singular values have no direction, so
+		// are filled in below
+		if s[j] > tol {
+			f.u[k] = w[j]
+			for i := range w[j] {
+				w[j][i] /= s[j]
+			}
+		}
+	}
+	f.u = complete(f.u, n, m)
+
+	if wide {
+		f.u, f.v = f.v, f.u
+		n, m = m, n
+	}
+	f.N, f.M = n, m
+	return f, nil
+}
+
+// Relative size of an off-diagonal element, compared with the diagonal, below
+// which two columns are treated as orthogonal
+const epsilon = 2.220446049250313e-16
+
+// Apply a plane rotation to a pair of vectors in place
+func rotate(x, y []float64, c, s float64) {
+	for i := range x {
+		a, b := x[i], y[i]
+		x[i] = c*a - s*b
+		y[i] = s*a + c*b
+	}
+}
+
+// Euclidean norm of a vector, hypot avoids overflow
+func norm(x []float64) float64 {
+	z := 0.0
+	for _, v := range x {
+		z = math.Hypot(z, v)
+	}
+	return z
+}
+
+// Fill in the missing (nil) vectors of `cols` and extend it to `k` vectors,
+// so all are orthonormal, by Gram-Schmidt on the standard basis of length `n`
+func complete(cols [][]float64, n, k int) [][]float64 {
+	z := make([][]float64, k)
+	copy(z, cols)
+
+	for j := range z {
+		if z[j] != nil {
+			continue
+		}
+		// The basis vector furthest from the span of those we have
+		var best []float64
+		bestNorm := -1.0
+		for e := 0; e < n; e++ {
+			c := make([]float64, n)
+			c[e] = 1
+			// Twice is enough for orthogonality to working precision
+			for pass := 0; pass < 2; pass++ {
+				for _, u := range z {
+					if u == nil {
+						continue
+					}
+					d := 0.0
+					for i := range c {
+						d += u[i] * c[i]
+					}
+					for i := range c {
+						c[i] -= d * u[i]
+					}
+				}
+			}
+			if nrm := norm(c); nrm > bestNorm {
+				best, bestNorm = c, nrm
+			}
+		}
+		for i := range best {
+			best[i] /= bestNorm
+		}
+		z[j] = best
+	}
+	return z
+}
+
+// Singular values below this are treated as zero, as in LAPACK and NumPy
+func svdTolerance(n, m int, biggest float64) float64 {
+	return float64(max(n, m)) * epsilon * biggest
+}
+
+// Returns the singular values in decreasing order
+func (f SVD) Values() []float64 {
+	return slices.Clone(f.s)
+}
+
+// Returns the `N` x K factor U, with orthonormal columns
+func (f SVD) U() Matrix {
+	return fromColumns(f.N, f.u)
+}
+
+// Returns the `M` x K factor V, with orthonormal columns
+func (f SVD) V() Matrix {
+	return fromColumns(f.M, f.v)
+}
+
+// Returns the `N` x `N` orthogonal factor U of the full decomposition
+func (f SVD) FullU() Matrix {
+	return fromColumns(f.N, complete(f.u, f.N, f.N))
+}
+
+// Returns the `M` x `M` orthogonal factor V of the full decomposition
+func (f SVD) FullV() Matrix {
+	return fromColumns(f.M, complete(f.v, f.M, f.M))
+}
+
+// Returns the number of singular values which aren't (numerically) zero
+func (f SVD) Rank() int {
+	if len(f.s) == 0 {
+		return 0
+	}
+	tol := svdTolerance(f.N, f.M, f.s[0])
+	r := 0
+	for _, s := range f.s {
+		if s > tol {
+			r++
+		}
+	}
+	return r
+}
+
+// Returns the 2-norm condition number, the ratio of the largest to the
+// smallest singular value. This is infinite for rank deficient matrices.
+func (f SVD) Cond() float64 {
+	if len(f.s) == 0 {
+		return 0
+	}
+	smallest := f.s[len(f.s)-1]
+	if smallest == 0 {
+		return math.Inf(1)
+	}
+	return f.s[0] / smallest
+}
+
+// Returns the `M` x `N` Moore-Penrose pseudo-inverse V S^+ U', where S^+
+// inverts the singular values which aren't (numerically) zero
+func (f SVD) PseudoInverse() Matrix {
+	z := NewDense(f.M, f.N)
+	for k := 0; k < f.Rank(); k++ {
+		for i, a := range f.v[k] {
+			a /= f.s[k]
+			row := z.Row(i)
+			for j, b := range f.u[k] {
+				row[j] += a * b
+			}
+		}
+	}
+	return z
+}
+
+// Returns the minimum norm least squares solution X minimising ||AX - b||,
+// X = V S^+ U'b. Unlike QR this has a unique answer when A is rank deficient.
+func (f SVD) Solve(b Matrix) (Matrix, error) {
+	bn, cols := b.Dims()
+	if bn != f.N {
+		return nil, fmt.Errorf("Expected `b` to have %d rows, got %d", f.N, bn)
+	}
+
+	bd := toDense(b)
+	z := NewDense(f.M, cols)
+	for k := 0; k < f.Rank(); k++ {
+		// u_k'b / s_k, for each column of b
+		c := make([]float64, cols)
+		for i, u := range f.u[k] {
+			for j := range c {
+				c[j] += u * bd[i*cols+j]
+			}
+		}
+		for j := range c {
+			c[j] /= f.s[k]
+		}
+		for i, v := range f.v[k] {
+			row := z.Row(i)
+			for j := range row {
+				row[j] += v * c[j]
+			}
+		}
+	}
+	return z, nil
+}
+
+// Create a `n` x len(cols) matrix with the given columns
+func fromColumns(n int, cols [][]float64) Matrix {
+	m := len(cols)
+	z := NewDense(n, m)
+	for j, col := range cols {
+		for i, v := range col {
+			z.Values[i*m+j] = v
+		}
+	}
+	return z
+}
+
+// Returns the rank of matrix `x`, the number of linearly independent columns
+func Rank(x Matrix) (int, error) {
+	svd, err := DecomposeSVD(x)
+	if err != nil {
+		return 0, err
+	}
+	return svd.Rank(), nil
+}
+
+// Returns the 2-norm condition number of matrix `x`
+func Cond(x Matrix) (float64, error) {
+	svd, err := DecomposeSVD(x)
+	if err != nil {
+		return 0, err
+	}
+	return svd.Cond(), nil
+}
+
+// Returns the Moore-Penrose pseudo-inverse of matrix `x`
+func PseudoInverse(x Matrix) (Matrix, error) {
+	svd, err := DecomposeSVD(x)
+	if err != nil {
+		return nil, err
+	}
+	return svd.PseudoInverse(), nil
+}
@@ -0,0 +1,243 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+)
+
+// Helper to rebuild U S V' from a decomposition
+func reconstruct(u Matrix, s []float64, v Matrix) Matrix {
+	n, k := u.Dims()
+	us := NewDense(n, k)
+	for i := 0; i < n; i++ {
+		for j := 0; j < k; j++ {
+			us.Set(i, j, u.At(i, j)*s[j])
+		}
+	}
+	z, _ := Multiply(us, Transpose(v))
+	return z
+}
+
+// Helper to check a matrix has orthonormal columns
+func isOrthonormal(x Matrix) bool {
+	_, m := x.Dims()
+	xtx, _ := Multiply(Transpose(x), x)
+	return near(xtx, Identity(m), 1e-12)
+}
+
+func TestDecomposeSVD(t *testing.T) {
+	type TestCase struct {
+		desc   string
+		input  Matrix
+		values []float64
+		rank   int
+	}
+
+	test_cases := []TestCase{
+		{
+			desc: "SVD of a wide 2x3 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{3, 2, 2},
+				{2, 3, -2},
+			}),
+			values: []float64{5, 3},
+			rank:   2,
+		},
+		{
+			desc: "SVD of a tall 3x2 matrix",
+			input: fromSliceOfSlices([][]float64{
+				{3, 2},
+				{2, 3},
+				{2, -2},
+			}),
+			values: []float64{5, 3},
+			rank:   2,
+		},
+		{
+			desc: "SVD of a diagonal matrix out of order",
+			input: fromSliceOfSlices([][]float64{
+				{1, 0, 0},
+				{0, -4, 0},
+				{0, 0, 2},
+			}),
+			values: []float64{4, 2, 1},
+			rank:   3,
+		},
+		{
+			desc: "SVD of a rank deficient matrix",
+			input: fromSliceOfSlices([][]float64{
+				{1, 2, 3},
+				{2, 4, 6},
+				{1, 0, 1},
+				{0, 2, 2},
+			}),
+			rank: 2,
+		},
+		{
+			desc:   "SVD of a zero matrix",
+			input:  Zero(3, 2),
+			values: []float64{0, 0},
+			rank:   0,
+		},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			svd, err := DecomposeSVD(test_case.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			n, m := test_case.input.Dims()
+			s := svd.Values()
+
+			for k := 1; k < len(s); k++ {
+				if s[k] > s[k-1] || s[k] < 0 {
+					t.Errorf("expected decreasing non-negative singular values, got %v", s)
+				}
+			}
+			if test_case.values != nil && !near(fromSliceOfSlices([][]float64{s}), fromSliceOfSlices([][]float64{test_case.values}), 1e-12) {
+				t.Errorf("expected to be the same, got %v, want %v", s, test_case.values)
+			}
+			if got := svd.Rank(); got != test_case.rank {
+				t.Errorf("expected to be the same, got %v, want %v", got, test_case.rank)
+			}
+
+			u, v := svd.U(), svd.V()
+			if un, uk := u.Dims(); un != n || uk != min(n, m) {
+				t.Errorf("expected U to be %d x %d, got %d x %d", n, min(n, m), un, uk)
+			}
+			if vm, vk := v.Dims(); vm != m || vk != min(n, m) {
+				t.Errorf("expected V to be %d x %d, got %d x %d", m, min(n, m), vm, vk)
+			}
+			if !isOrthonormal(u) || !isOrthonormal(v) {
+				t.Errorf("expected orthonormal columns, got U = %v, V = %v", u, v)
+			}
+			if got := reconstruct(u, s, v); !near(got, test_case.input, 1e-12) {
+				t.Errorf("expected U S V' = A, got %v, want %v", got, test_case.input)
+			}
+
+			// The full decomposition pads S with zeros
+			fu, fv := svd.FullU(), svd.FullV()
+			if !isOrthonormal(fu) || !isOrthonormal(fv) {
+				t.Errorf("expected orthogonal matrices, got U = %v, V = %v", fu, fv)
+			}
+			sigma := NewDense(n, m)
+			for k, v := range s {
+				sigma.Set(k, k, v)
+			}
+			us, _ := Multiply(fu, sigma)
+			if got, _ := Multiply(us, Transpose(fv)); !near(got, test_case.input, 1e-12) {
+				t.Errorf("expected U S V' = A, got %v, want %v", got, test_case.input)
+			}
+		})
+	}
+}
+
+func TestCondition(t *testing.T) {
+	t.Run("condition number of a diagonal matrix", func(t *testing.T) {
+		got, err := Cond(NewDiagonal([]float64{1, -100, 10}))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if math.Abs(got-100) > 1e-12 {
+			t.Errorf("expected to be the same, got %v, want %v", got, 100)
+		}
+	})
+
+	t.Run("rank deficient matrices are infinitely conditioned", func(t *testing.T) {
+		got, _ := Cond(fromSliceOfSlices([][]float64{{1, 2}, {2, 4}, {3, 6}}))
+		if got < 1e15 {
+			t.Errorf("expected a huge condition number, got %v", got)
+		}
+		if r, _ := Rank(fromSliceOfSlices([][]float64{{1, 2}, {2, 4}, {3, 6}})); r != 1 {
+			t.Errorf("expected to be the same, got %v, want %v", r, 1)
+		}
+	})
+}
+
+func TestPseudoInverse(t *testing.T) {
+	t.Run("least squares for full column rank", func(t *testing.T) {
+		x := fromSliceOfSlices([][]float64{
+			{1, 1},
+			{1, 2},
+			{1, 3},
+			{1, 4},
+			{1, 5},
+		})
+		pinv, err := PseudoInverse(x)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		y := fromSliceOfSlices([][]float64{{1}, {3}, {2}, {5}, {4}})
+		got, _ := Multiply(pinv, y)
+		want := fromSliceOfSlices([][]float64{{0.6}, {0.8}})
+		if !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("the inverse for invertible matrices", func(t *testing.T) {
+		x := fromSliceOfSlices([][]float64{{4, 7}, {2, 6}})
+		got, _ := PseudoInverse(x)
+		want, _ := Inverse(x)
+		if !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Moore-Penrose conditions for rank deficient matrices", func(t *testing.T) {
+		for _, x := range []Matrix{
+			fromSliceOfSlices([][]float64{{1, 2, 3}, {2, 4, 6}, {1, 0, 1}, {0, 2, 2}}),
+			fromSliceOfSlices([][]float64{{1, 2, 2, 1}, {2, 4, 4, 2}}),
+		} {
+			p, _ := PseudoInverse(x)
+			xp, _ := Multiply(x, p)
+			px, _ := Multiply(p, x)
+			if xpx, _ := Multiply(xp, x); !near(xpx, x, 1e-12) {
+				t.Errorf("expected A A+ A = A, got %v, want %v", xpx, x)
+			}
+			if pxp, _ := Multiply(px, p); !near(pxp, p, 1e-12) {
+				t.Errorf("expected A+ A A+ = A+, got %v, want %v", pxp, p)
+			}
+			if !near(xp, Transpose(xp), 1e-12) || !near(px, Transpose(px), 1e-12) {
+				t.Errorf("expected A A+ and A+ A to be symmetric, got %v and %v", xp, px)
+			}
+		}
+	})
+}
+
+func TestSVDSolve(t *testing.T) {
+	t.Run("minimum norm solution of collinear columns", func(t *testing.T) {
+		// The second column is twice the first, so b = x1 has solutions (1 - 2c, c)
+		// and the smallest of them is (0.2, 0.4)
+		x := fromSliceOfSlices([][]float64{
+			{1, 2},
+			{2, 4},
+			{3, 6},
+		})
+		b := fromSliceOfSlices([][]float64{{1}, {2}, {3}})
+		svd, _ := DecomposeSVD(x)
+		got, err := svd.Solve(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := fromSliceOfSlices([][]float64{{0.2}, {0.4}})
+		if !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("agrees with the pseudo-inverse", func(t *testing.T) {
+		x := fromSliceOfSlices([][]float64{{1, 2, 3}, {2, 4, 6}, {1, 0, 1}, {0, 2, 2}})
+		b := fromSliceOfSlices([][]float64{{1, 0}, {0, 1}, {2, 0}, {1, 1}})
+		svd, _ := DecomposeSVD(x)
+		got, _ := svd.Solve(b)
+		want, _ := Multiply(svd.PseudoInverse(), b)
+		if !near(got, want, 1e-12) {
+			t.Errorf("expected to be the same, got %v, want %v", got, want)
+		}
+		if _, err := svd.Solve(Zero(3, 1)); err == nil {
+			t.Errorf("expected solve to fail")
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package matrix
+
+import (
+	"fmt"
+	"slices"
+)
+
+/*
+A square matrix which is zero below (`Upper`) or above (lower) the diagonal.
+It is stored in full, row major, like Dense.
+*/
+type Triangular struct {
+	Values []float64
+	N      int
+	Upper  bool
+}
+
+// Create a `n` x `n` triangular matrix of zeros
+func NewTriangular(n int, upper bool) *Triangular {
+	return &Triangular{
+		Values: make([]float64, n*n),
+		N:      n,
+		Upper:  upper,
+	}
+}
+
+// Whether (i, j) is in the triangle which may be non-zero
+func (x *Triangular) inTriangle(i, j int) bool {
+	if x.Upper {
+		return i <= j
+	}
+	return i >= j
+}
+
+func (x *Triangular) Dims() (int, int) {
+	return x.N, x.N
+}
+
+// Get the value in a matrix at a point
+func (x *Triangular) At(i, j int) float64 {
+	return x.Values[i*x.N+j]
+}
+
+// Set a matrix value at a point, outside of the triangle can only be zero
+func (x *Triangular) Set(i, j int, v float64) error {
+	if i >= x.N || j >= x.N || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	if !x.inTriangle(i, j) && v != 0 {
+		return fmt.Errorf("Can't set (%d, %d) of a triangular matrix", i, j)
+	}
+	x.Values[i*x.N+j] = v
+	return nil
+}
+
+// Call `fn` for each non-zero element, in row major order
+func (x *Triangular) NonZeros(fn func(i, j int, v float64)) {
+	for i := 0; i < x.N; i++ {
+		lo, hi := 0, i+1
+		if x.Upper {
+			lo, hi = i, x.N
+		}
+		for j := lo; j < hi; j++ {
+			if v := x.Values[i*x.N+j]; v != 0 {
+				fn(i, j, v)
+			}
+		}
+	}
+}
+
+// Copy a matrix in it's entirety
+func (x *Triangular) Copy() *Triangular {
+	return &Triangular{
+		Values: slices.Clone(x.Values),
+		N:      x.N,
+		Upper:  x.Upper,
+	}
+}
@@ -0,0 +1,182 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// A column vector. It is also an N x 1 Matrix, so can be passed to anything
+// expecting one.
+type Vector []float64
+
+// Create a vector of `n` zeros
+func NewVector(n int) Vector {
+	return make(Vector, n)
+}
+
+// Returns the single column of `x` as a vector
+func ToVector(x Matrix) (Vector, error) {
+	n, m := x.Dims()
+	if m != 1 {
+		return nil, fmt.Errorf("Expected a column vector, got a %d x %d", n, m)
+	}
+	if v, ok := x.(Vector); ok {
+		return v.Copy(), nil
+	}
+	z := NewVector(n)
+	x.NonZeros(func(i, j int, v float64) {
+		z[i] = v
+	})
+	return z, nil
+}
+
+// Get the dimensions of the vector as a matrix, `n` x 1
+func (v Vector) Dims() (int, int) {
+	return len(v), 1
+}
+
+// Get the value at a point, `j` is always 0
+func (v Vector) At(i, j int) float64 {
+	return v[i]
+}
+
+// Set a value at a point, `j` must be 0
+func (v Vector) Set(i, j int, x float64) error {
+	if i >= len(v) || j != 0 || i < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	v[i] = x
+	return nil
+}
+
+// Call `fn` for each non-zero element, in order
+func (v Vector) NonZeros(fn func(i, j int, v float64)) {
+	for i, x := range v {
+		if x != 0 {
+			fn(i, 0, x)
+		}
+	}
+}
+
+// Copy a vector in its entirety
+func (v Vector) Copy() Vector {
+	return slices.Clone(v)
+}
+
+// Helper for consistent error messaging
+func sameLength(v, w Vector) error {
+	if len(v) != len(w) {
+		return fmt.Errorf("Expected vectors of the same length, got %d and %d", len(v), len(w))
+	}
+	return nil
+}
+
+// Returns the inner product of `v` and `w`
+func (v Vector) Dot(w Vector) (float64, error) {
+	if err := sameLength(v, w); err != nil {
+		return 0, err
+	}
+	return dot(v, w), nil
+}
+
+// Returns the `p`-norm of `v`, the largest absolute value when `p` is infinite
+func (v Vector) Norm(p float64) float64 {
+	switch {
+	case p == 1:
+		z := 0.0
+		for _, x := range v {
+			z += math.Abs(x)
+		}
+		return z
+	case p == 2:
+		return norm(v)
+	case math.IsInf(p, 1):
+		z := 0.0
+		for _, x := range v {
+			z = max(z, math.Abs(x))
+		}
+		return z
+	}
+	z := 0.0
+	for _, x := range v {
+		z += math.Pow(math.Abs(x), p)
+	}
+	return math.Pow(z, 1/p)
+}
+
+// Adds `a` times `x` to `v` in place, BLAS's axpy
+func (v Vector) Axpy(a float64, x Vector) error {
+	if err := sameLength(v, x); err != nil {
+		return err
+	}
+	for i := range v {
+		v[i] += a * x[i]
+	}
+	return nil
+}
+
+// Returns `v` multiplied by `a`
+func (v Vector) Scale(a float64) Vector {
+	z := v.Copy()
+	scaleVec(z, a)
+	return z
+}
+
+// Returns the sum of the elements of `v`
+func (v Vector) Sum() float64 {
+	z := 0.0
+	for _, x := range v {
+		z += x
+	}
+	return z
+}
+
+// Returns the mean of the elements of `v`, NaN if there are none
+func (v Vector) Mean() float64 {
+	return v.Sum() / float64(len(v))
+}
+
+// Returns the vector with elements `fn`(v_i, w_i)
+func (v Vector) elementwise(w Vector, fn func(a, b float64) float64) (Vector, error) {
+	if err := sameLength(v, w); err != nil {
+		return nil, err
+	}
+	z := NewVector(len(v))
+	for i := range v {
+		z[i] = fn(v[i], w[i])
+	}
+	return z, nil
+}
+
+// Returns `v` + `w`
+func (v Vector) Add(w Vector) (Vector, error) {
+	return v.elementwise(w, func(a, b float64) float64 { return a + b })
+}
+
+// Returns `v` - `w`
+func (v Vector) Sub(w Vector) (Vector, error) {
+	return v.elementwise(w, func(a, b float64) float64 { return a - b })
+}
+
+// Returns the elementwise product of `v` and `w`
+func (v Vector) MulElem(w Vector) (Vector, error) {
+	return v.elementwise(w, func(a, b float64) float64 { return a * b })
+}
+
+// Returns the elementwise quotient of `v` and `w`
+func (v Vector) DivElem(w Vector) (Vector, error) {
+	return v.elementwise(w, func(a, b float64) float64 { return a / b })
+}
+
+// Returns the product of matrix `x` and vector `v`, only visiting the
+// non-zeros of `x`
+func MultiplyVector(x Matrix, v Vector) (Vector, error) {
+	n, m := x.Dims()
+	if m != len(v) {
+		return nil, fmt.Errorf("Expected the number of `x` columns to be the same as the length of `v`")
+	}
+	z := NewVector(n)
+	mulVec(x, v, z)
+	return z, nil
+}
@@ -0,0 +1,127 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVectorNorm(t *testing.T) {
+	v := Vector{3, -4, 0, 12}
+
+	type TestCase struct {
+		desc string
+		p    float64
+		want float64
+	}
+
+	test_cases := []TestCase{
+		{desc: "1-norm", p: 1, want: 19},
+		{desc: "2-norm", p: 2, want: 13},
+		{desc: "infinity norm", p: math.Inf(1), want: 12},
+		{desc: "3-norm", p: 3, want: math.Cbrt(27 + 64 + 1728)},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got := v.Norm(test_case.p)
+			if math.Abs(got-test_case.want) > 1e-12 {
+				t.Errorf("expected to be the same, got %v, want %v", got, test_case.want)
+			}
+		})
+	}
+}
+
+func TestVectorOperations(t *testing.T) {
+	v := Vector{1, 2, 3}
+	w := Vector{4, -5, 6}
+
+	d, err := v.Dot(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d != 12 {
+		t.Errorf("expected to be the same, got %v, want %v", d, 12)
+	}
+
+	if got := v.Sum(); got != 6 {
+		t.Errorf("expected to be the same, got %v, want %v", got, 6)
+	}
+	if got := v.Mean(); got != 2 {
+		t.Errorf("expected to be the same, got %v, want %v", got, 2)
+	}
+	if got := v.Scale(2); !Equal(got, Vector{2, 4, 6}) || v[0] != 1 {
+		t.Errorf("expected a scaled copy, got %v", got)
+	}
+
+	type TestCase struct {
+		desc string
+		fn   func(Vector) (Vector, error)
+		want Vector
+	}
+
+	test_cases := []TestCase{
+		{desc: "add", fn: v.Add, want: Vector{5, -3, 9}},
+		{desc: "subtract", fn: v.Sub, want: Vector{-3, 7, -3}},
+		{desc: "multiply", fn: v.MulElem, want: Vector{4, -10, 18}},
+		{desc: "divide", fn: v.DivElem, want: Vector{0.25, -0.4, 0.5}},
+	}
+
+	for _, test_case := range test_cases {
+		t.Run(test_case.desc, func(t *testing.T) {
+			got, err := test_case.fn(w)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !near(got, test_case.want, 1e-12) {
+				t.Errorf("expected to be the same, got %v, want %v", got, test_case.want)
+			}
+			if _, err := test_case.fn(Vector{1}); err == nil {
+				t.Errorf("expected an error for vectors of different lengths")
+			}
+		})
+	}
+}
+
+func TestVectorAxpy(t *testing.T) {
+	v := Vector{1, 2, 3}
+	if err := v.Axpy(2, Vector{1, 0, -1}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := (Vector{3, 2, 1}); !Equal(v, want) {
+		t.Errorf("expected to be the same, got %v, want %v", v, want)
+	}
+	if err := v.Axpy(1, Vector{1}); err == nil {
+		t.Errorf("expected an error for vectors of different lengths")
+	}
+}
+
+func TestVectorAsMatrix(t *testing.T) {
+	x := fromSliceOfSlices([][]float64{{1, 2}, {0, 1}, {3, 0}})
+	v := Vector{1, -1}
+
+	got, err := MultiplyVector(x, v)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want, _ := Multiply(x, v) // A Vector is an N x 1 Matrix
+	if !Equal(got, want) {
+		t.Errorf("expected to be the same, got %v, want %v", got, want)
+	}
+	if _, err := MultiplyVector(x, Vector{1, 2, 3}); err == nil {
+		t.Errorf("expected an error for mismatched dimensions")
+	}
+
+	column, err := ToVector(fromSliceOfSlices([][]float64{{1}, {0}, {2}}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := (Vector{1, 0, 2}); !Equal(column, want) {
+		t.Errorf("expected to be the same, got %v, want %v", column, want)
+	}
+	if _, err := ToVector(x); err == nil {
+		t.Errorf("expected an error converting a matrix with two columns")
+	}
+	if err := v.Set(0, 1, 2); err == nil {
+		t.Errorf("expected an error setting outside the column")
+	}
+}
@@ -0,0 +1,50 @@
+package matrix
+
+import "fmt"
+
+/*
+A View is a window onto the `N` x `M` block of another matrix starting at row
+`I` and column `J`. It shares the underlying matrix, so setting an element of
+the view sets it in the original.
+*/
+type View struct {
+	X          Matrix
+	I, J, N, M int
+}
+
+// Create a view of the `n` x `m` block of `x` starting at (i, j)
+func NewView(x Matrix, i, j, n, m int) (*View, error) {
+	xn, xm := x.Dims()
+	if i < 0 || j < 0 || n < 0 || m < 0 || i+n > xn || j+m > xm {
+		return nil, fmt.Errorf("View of %d x %d at (%d, %d) is outside of a %d x %d matrix", n, m, i, j, xn, xm)
+	}
+	return &View{X: x, I: i, J: j, N: n, M: m}, nil
+}
+
+func (x *View) Dims() (int, int) {
+	return x.N, x.M
+}
+
+// Get the value in a matrix at a point
+func (x *View) At(i, j int) float64 {
+	return x.X.At(x.I+i, x.J+j)
+}
+
+// Set a matrix value at a point
+func (x *View) Set(i, j int, v float64) error {
+	if i >= x.N || j >= x.M || i < 0 || j < 0 {
+		return fmt.Errorf("Invalid address")
+	}
+	return x.X.Set(x.I+i, x.J+j, v)
+}
+
+// Call `fn` for each non-zero element, in row major order
+func (x *View) NonZeros(fn func(i, j int, v float64)) {
+	for i := 0; i < x.N; i++ {
+		for j := 0; j < x.M; j++ {
+			if v := x.At(i, j); v != 0 {
+				fn(i, j, v)
+			}
+		}
+	}
+}